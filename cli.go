@@ -168,6 +168,34 @@ type Cli struct {
 	// VerbsRequired is true then USAGE line shows VERB rather than [VERB]
 	VerbsRequired bool
 
+	// GNUStyle is true then options are parsed and documented GNU/POSIX
+	// style, e.g. "-o, --output", "--output=FILE", bundled short options
+	// like "-qp" and "--" to end option processing. It also applies to
+	// verbs created with NewVerb().
+	GNUStyle bool
+
+	// Interspersed is true then options may appear after positional
+	// arguments, e.g. "myapp input.json -o out.json". Option processing
+	// continues until "--" or a verb name. It also applies to verbs
+	// created with NewVerb().
+	Interspersed bool
+
+	// DotEnvFiles lists dotenv files (e.g. ".env") read by ParseEnv for
+	// variables not set in the environment, later files override earlier
+	// ones and missing files are skipped.
+	DotEnvFiles []string
+
+	// NegatableBools is true then every boolean option with a long name
+	// also accepts a "no-" form, e.g. "-no-color", documented as
+	// "-[no-]color". Use Negatable() to add them to chosen options only.
+	// It also applies to verbs created with NewVerb().
+	NegatableBools bool
+
+	// ResponseFiles is true then arguments like "@args.txt" are replaced
+	// by the arguments read from the file before options are parsed,
+	// see ExpandResponseFiles().
+	ResponseFiles bool
+
 	// application name based on os.Args[0]
 	appName string
 	// application version based on string passed in New
@@ -176,8 +204,23 @@ type Cli struct {
 	env map[string]*EnvAttribute
 	// description of additoinal command line parameters
 	params []string
-	// description of short/long options and their doc strings
-	options map[string]string
+	// paramSpec describes the positional parameters validated after parsing
+	paramSpec []*Param
+	// paramValues holds the converted positional parameters by name
+	paramValues map[string][]interface{}
+	// options describes the short/long options in the order declared
+	options []*Option
+	// group is the current group name assigned to options as they are declared
+	group string
+	// constraints holds rules between options checked after parsing
+	constraints []*constraint
+	// config describes the configuration file, if any
+	config *configFile
+	// standard holds the options added by AddStandardOptions()
+	standard *StandardOptions
+
+	// FlagSet holds the parsable options associated with the cli.
+	FlagSet *flag.FlagSet
 
 	/*
 		// (depreciated) non-flag options, e.g. in the command line "go test", "test" would be the action string.
@@ -196,7 +239,7 @@ type Cli struct {
 func NewCli(version string) *Cli {
 	appName := path.Base(os.Args[0])
 	env := make(map[string]*EnvAttribute)
-	options := make(map[string]string)
+	options := []*Option{}
 	/*
 		//NOTE: actions is depreciated
 		actions := make(map[string]*Action)
@@ -205,6 +248,9 @@ func NewCli(version string) *Cli {
 	verbs := make(map[string]*Verb)
 	documentation := make(map[string][]byte)
 	sectionNo := 0
+	// NOTE: option errors are returned by ParseArgs(), it is up to the
+	// caller to decide if we exit.
+	flagSet := flag.NewFlagSet(appName, flag.ContinueOnError)
 	return &Cli{
 		In:            os.Stdin,
 		Out:           os.Stdout,
@@ -216,6 +262,7 @@ func NewCli(version string) *Cli {
 		env:           env,
 		params:        []string{},
 		options:       options,
+		FlagSet:       flagSet,
 		/*
 			actions:       actions,
 		*/
@@ -295,170 +342,349 @@ func splitOps(names string) []string {
 	return ops
 }
 
-func opsLabel(ops []string) string {
+// opsLabel formats option names for documentation. If gnu is true
+// then long option names are prefixed with "--" rather than "-".
+func opsLabel(ops []string, gnu bool) string {
 	parts := []string{}
 	for _, op := range ops {
-		parts = append(parts, "-"+op)
+		if gnu && len(op) > 1 {
+			parts = append(parts, "--"+op)
+		} else {
+			parts = append(parts, "-"+op)
+		}
 	}
 	return strings.Join(parts, ", ")
 }
 
-// BoolVar updates c.options doc strings, then splits options and calls flag.BoolVar()
-func (c *Cli) BoolVar(p *bool, names string, value bool, usage string) {
+// Var updates c.options doc strings, then splits options and calls c.FlagSet.Var()
+// for each name. It lets you define options with your own flag.Value types.
+// A back-quoted name in usage is shown as the value placeholder in the
+// documentation, e.g. "write output to `FILE`" documents "-o, -output FILE".
+func (c *Cli) Var(value flag.Value, names string, usage string) {
 	// Prep to hand off to the flag package
 	ops := splitOps(names)
 	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
+	o := newOption(ops, value, usage, len(c.options))
+	o.Group = c.group
+	c.options = append(c.options, o)
 	// process with flag package
 	for _, op := range ops {
-		flag.BoolVar(p, op, value, usage)
+		c.FlagSet.Var(value, op, usage)
 	}
 }
 
-// IntVar updates c.options doc strings, then splits options and calls flag.IntVar()
+// OptionGroup sets the group name for the options declared after it,
+// e.g. "Standard Options" or "Application Options". Groups are documented
+// in the order they are first used. An empty string ends the group.
+func (c *Cli) OptionGroup(name string) {
+	c.group = name
+}
+
+// Func defines an option with the names and usage, each time the option
+// is seen fn is called with the option's value. If fn returns a non-nil
+// error it is treated as an invalid value.
+func (c *Cli) Func(names string, usage string, fn func(string) error) {
+	c.Var(funcValue(fn), names, usage)
+}
+
+// BoolVar defines a bool option with the names, default value and usage, see Var()
+func (c *Cli) BoolVar(p *bool, names string, value bool, usage string) {
+	c.Var(newBoolValue(value, p), names, usage)
+}
+
+// IntVar defines an int option with the names, default value and usage, see Var()
 func (c *Cli) IntVar(p *int, names string, value int, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.IntVar(p, op, value, usage)
-	}
+	c.Var(newIntValue(value, p), names, usage)
+}
+
+// CountVar defines a counter option, each time it is given the count is
+// raised by one, e.g. "-v -v -v" or "-vvv" sets 3.
+func (c *Cli) CountVar(p *int, names string, value int, usage string) {
+	c.Var(newCounterValue(value, p), names, usage)
 }
 
-// Int64Var updates c.options doc strings, then splits options and calls flag.Int64Var()
+// Int64Var defines an int64 option with the names, default value and usage, see Var()
 func (c *Cli) Int64Var(p *int64, names string, value int64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.Int64Var(p, op, value, usage)
-	}
+	c.Var(newInt64Value(value, p), names, usage)
 }
 
-// UintVar updates c.options doc strings, then splits options and calls flag.Int64Var()
+// UintVar defines a uint option with the names, default value and usage, see Var()
 func (c *Cli) UintVar(p *uint, names string, value uint, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.UintVar(p, op, value, usage)
-	}
+	c.Var(newUintValue(value, p), names, usage)
 }
 
-// Uint64Var updates c.options doc strings, then splits options and calls flag.Int64Var()
+// Uint64Var defines a uint64 option with the names, default value and usage, see Var()
 func (c *Cli) Uint64Var(p *uint64, names string, value uint64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.Uint64Var(p, op, value, usage)
-	}
+	c.Var(newUint64Value(value, p), names, usage)
 }
 
-// StringVar updates c.options doc strings, then splits options and calls flag.StringVar()
+// StringVar defines a string option with the names, default value and usage, see Var()
 func (c *Cli) StringVar(p *string, names string, value string, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.StringVar(p, op, value, usage)
-	}
+	c.Var(newStringValue(value, p), names, usage)
 }
 
-// Float64Var updates c.options doc strings, then splits options and calls flag.Float64Var()
+// Float64Var defines a float64 option with the names, default value and usage, see Var()
 func (c *Cli) Float64Var(p *float64, names string, value float64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.Float64Var(p, op, value, usage)
-	}
+	c.Var(newFloat64Value(value, p), names, usage)
 }
 
-// DurationVar updates c.options doc strings, then splits options and calls flag.DurationVar()
+// DurationVar defines a time.Duration option with the names, default value and usage, see Var()
 func (c *Cli) DurationVar(p *time.Duration, names string, value time.Duration, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	c.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		flag.DurationVar(p, op, value, usage)
+	c.Var(newDurationValue(value, p), names, usage)
+}
+
+// TimeVar defines a time.Time option, see ParseTime for the formats accepted
+func (c *Cli) TimeVar(p *time.Time, names string, value time.Time, usage string) {
+	c.Var(newTimeValue(value, p), names, usage)
+}
+
+// FileVar defines an option naming an existing file. A leading "~" and
+// $VAR are expanded and the file is checked when the option is set.
+func (c *Cli) FileVar(p *string, names string, value string, usage string) {
+	c.Var(newPathValue(value, fileKind, p), names, usage)
+}
+
+// DirVar defines an option naming an existing directory. A leading "~"
+// and $VAR are expanded and the directory is checked when the option is set.
+func (c *Cli) DirVar(p *string, names string, value string, usage string) {
+	c.Var(newPathValue(value, dirKind, p), names, usage)
+}
+
+// WritablePathVar defines an option naming a file that can be created or
+// overwritten. A leading "~" and $VAR are expanded and the path is
+// checked when the option is set.
+func (c *Cli) WritablePathVar(p *string, names string, value string, usage string) {
+	c.Var(newPathValue(value, writableKind, p), names, usage)
+}
+
+// StringSliceVar defines a []string option with the names, default value
+// and usage. The option may be repeated (e.g. -i a.json -i b.json), the
+// first occurrence replaces the default. If sep is not an empty string
+// each value is also split on sep (e.g. -fields title,doi,author).
+func (c *Cli) StringSliceVar(p *[]string, names string, value []string, sep string, usage string) {
+	c.Var(newStringSliceValue(value, sep, p), names, usage)
+}
+
+// IntSliceVar defines a []int option with the names, default value
+// and usage. The option may be repeated (e.g. -n 1 -n 2), the first
+// occurrence replaces the default. If sep is not an empty string
+// each value is also split on sep (e.g. -n 1,2,3).
+func (c *Cli) IntSliceVar(p *[]int, names string, value []int, sep string, usage string) {
+	c.Var(newIntSliceValue(value, sep, p), names, usage)
+}
+
+// ChoiceVar defines a string option with the names, default value and
+// usage whose value must be one of choices (e.g. json, csv, tsv). Any
+// other value is rejected when the options are parsed. A default that
+// isn't empty or one of the choices panics, as a redefined flag does.
+func (c *Cli) ChoiceVar(p *string, names string, value string, choices []string, usage string) {
+	c.Var(newChoiceValue(names, value, choices, p), names, usage)
+}
+
+// Required marks the named options as required. Options can be named
+// by any of their names, e.g. "o" or "output" for "o,output". When
+// parsed any required options not given are reported together. An
+// option set from a configuration file or environment variable counts
+// as given.
+func (c *Cli) Required(names ...string) error {
+	return setRequired(c.options, names)
+}
+
+// MutuallyExclusive declares that at most one of the named options may
+// be given, e.g. "generate-markdown", "generate-manpage". As with the
+// other constraints options set from a configuration file or environment
+// variable count as given.
+func (c *Cli) MutuallyExclusive(names ...string) error {
+	return c.addConstraint(exclusiveConstraint, "", names)
+}
+
+// Requires declares that if the named option is given the required
+// options must be given too.
+func (c *Cli) Requires(name string, required ...string) error {
+	return c.addConstraint(requiresConstraint, name, required)
+}
+
+// AtLeastOneOf declares that one or more of the named options must be given.
+func (c *Cli) AtLeastOneOf(names ...string) error {
+	return c.addConstraint(atLeastOneConstraint, "", names)
+}
+
+// Implies declares that if the named option is given the implied options
+// are set too, unless given explicitly. Implied options can include a
+// value (e.g. "format=json"), otherwise they are set to "true".
+func (c *Cli) Implies(name string, implied ...string) error {
+	return c.addConstraint(impliesConstraint, name, implied)
+}
+
+// addConstraint validates and saves a constraint
+func (c *Cli) addConstraint(kind int, name string, names []string) error {
+	r, err := newConstraint(c.options, kind, name, names)
+	if err != nil {
+		return err
 	}
+	c.constraints = append(c.constraints, r)
+	return nil
+}
+
+// Negatable adds "no-" forms of the named boolean options, e.g.
+// "-no-color" sets -color to false. They are documented as "-[no-]color".
+// Set c.NegatableBools to add them to all boolean options.
+func (c *Cli) Negatable(names ...string) error {
+	return setNegatable(c.FlagSet, c.options, names)
+}
+
+// applyNegatable adds the "no-" forms of all boolean options if
+// c.NegatableBools is true, options may be declared after setting it.
+func (c *Cli) applyNegatable() {
+	if c.NegatableBools {
+		negateBools(c.FlagSet, c.options)
+	}
+}
+
+// Hide marks the named options as hidden, they are accepted when parsing
+// but not included in the documentation.
+func (c *Cli) Hide(names ...string) error {
+	return setHidden(c.options, names)
+}
+
+// Deprecate marks the named option as deprecated. The option is still
+// accepted but a warning is written when it is used. replacedBy names the
+// replacement option (e.g. "package") or explains why it was retired.
+// Deprecated options are listed in a "Deprecated" note in the documentation.
+func (c *Cli) Deprecate(name string, replacedBy string) error {
+	return setDeprecated(c.options, name, replacedBy)
 }
 
 // Option returns an option's document string or unsupported string
 func (c *Cli) Option(op string) string {
-	op = strings.Trim(op, " ")
-	for k, doc := range c.options {
-		if strings.Contains(k, op) {
-			return doc
-		}
+	if o := findOption(c.options, op); o != nil {
+		return o.Usage
 	}
-	return fmt.Sprintf("%q is an unsupported option", op)
+	return fmt.Sprintf("%q is an unsupported option", strings.TrimSpace(op))
+}
+
+// LookupOption returns the Option with a name exactly matching name
+// (e.g. "o", "-o" or "output" for "o,output") or nil if not found.
+func (c *Cli) LookupOption(name string) *Option {
+	return findOption(c.options, name)
+}
+
+// OptionList returns the options in the order they were declared
+func (c *Cli) OptionList() []*Option {
+	return append([]*Option{}, c.options...)
 }
 
 // Options returns a map of option values and doc strings
 func (c *Cli) Options() map[string]string {
-	return c.options
+	c.applyNegatable()
+	return optionDocs(c.options, c.GNUStyle)
 }
 
-// ParseOptions envokes flag.Parse() updating variables set in AddOptions
+// ParseOptions parses the options in os.Args updating variables set in AddOptions.
+// If the options can't be parsed the error is written to c.Eout and the
+// program exits.
 func (c *Cli) ParseOptions() {
-	flag.Parse()
+	args, err := c.expandArgs(os.Args[1:])
+	if err != nil {
+		c.exitOnParseError(err)
+	}
+	c.parseOptions(args)
+}
+
+// parseOptions parses the options in args, already expanded by
+// expandArgs(), exiting on error.
+func (c *Cli) parseOptions(args []string) {
+	p := c.newParser()
+	if err := p.parse(args); err != nil {
+		c.exitOnParseError(err)
+	}
+	if err := c.validate(); err != nil {
+		c.exitOnParseError(err)
+	}
 	//FIXME: need to parse options for verbs is present...
 }
 
-// Parse process both the environment and any flags
+// newParser returns a parser for the options of the cli
+func (c *Cli) newParser() *parser {
+	c.applyNegatable()
+	stopAt := map[string]bool{}
+	for name := range c.verbs {
+		stopAt[name] = true
+	}
+	return &parser{fs: c.FlagSet, gnu: c.GNUStyle, options: c.options, eout: c.Eout, interspersed: c.Interspersed, stopAt: stopAt}
+}
+
+// exitOnParseError reports err and exits, it mimics the flag package's
+// flag.ExitOnError behavior.
+func (c *Cli) exitOnParseError(err error) {
+	if err == flag.ErrHelp {
+		c.Usage(c.Eout)
+		os.Exit(0)
+	}
+	fmt.Fprintf(c.Eout, "%s\n", err)
+	os.Exit(2)
+}
+
+// Parse process the configuration file, the environment and any flags.
+// If the options can't be parsed the error is written to c.Eout and the
+// program exits.
+// Use ParseArgs() if you need to handle the error yourself.
 func (c *Cli) Parse() error {
-	err := c.ParseEnv()
+	args, err := c.expandArgs(os.Args[1:])
 	if err != nil {
 		return err
 	}
-	c.ParseOptions()
+	if err := c.loadConfig(args); err != nil {
+		return err
+	}
+	err = c.ParseEnv()
+	if err != nil {
+		return err
+	}
+	// NOTE: response files are read once, config and options share args
+	c.parseOptions(args)
 	return nil
 }
 
-// Args returns flag.Args()
+// ParseArgs processes the configuration file, the environment then the
+// options in args (e.g. os.Args[1:]). Unlike Parse() it never exits,
+// option problems are returned as a *ParseError (or flag.ErrHelp if -h
+// or -help was given but not defined) so the caller can decide how to
+// report them.
+// Missing required options and environment variables are reported
+// together in a single *ParseError, followed by any option constraints
+// not met. Response files are expanded first if c.ResponseFiles is true.
+func (c *Cli) ParseArgs(args []string) error {
+	args, err := c.expandArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := c.loadConfig(args); err != nil {
+		return err
+	}
+	if err := c.ParseEnv(); err != nil {
+		return err
+	}
+	p := c.newParser()
+	if err := p.parse(args); err != nil {
+		return err
+	}
+	return c.validate()
+}
+
+// Args returns c.FlagSet.Args()
 func (c *Cli) Args() []string {
-	return flag.Args()
+	return c.FlagSet.Args()
 }
 
 // Arg returns an argument by pos index
 func (c *Cli) Arg(i int) string {
-	return flag.Arg(i)
+	return c.FlagSet.Arg(i)
 }
 
-// NArg returns flag.NArg()
+// NArg returns c.FlagSet.NArg()
 func (c *Cli) NArg() int {
-	return flag.NArg()
+	return c.FlagSet.NArg()
 }
 
 // Set Params generates explicit documentation for expected parameters
@@ -469,12 +695,52 @@ func (c *Cli) SetParams(params ...string) {
 	}
 }
 
+// AddParams describes the positional parameters. After parsing the
+// arguments are checked against them and converted to their type.
+// They are also used to document the parameters in the USAGE line.
+func (c *Cli) AddParams(params ...Param) error {
+	spec := append([]*Param{}, c.paramSpec...)
+	for i := range params {
+		param := params[i]
+		spec = append(spec, &param)
+	}
+	if err := checkParamSpec(spec); err != nil {
+		return err
+	}
+	c.paramSpec = spec
+	return nil
+}
+
+// ParamValues returns the converted values of the named positional
+// parameter, a repeating parameter may have more than one.
+func (c *Cli) ParamValues(name string) []interface{} {
+	return c.paramValues[name]
+}
+
+// ParamValue returns the first converted value of the named positional
+// parameter or nil if it wasn't given.
+func (c *Cli) ParamValue(name string) interface{} {
+	if values := c.paramValues[name]; len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// paramNames returns the parameters as shown in the USAGE line
+func (c *Cli) paramNames() []string {
+	if len(c.paramSpec) > 0 {
+		return paramUsage(c.paramSpec)
+	}
+	return c.params
+}
+
 // NewVerb associates a verb, synopsis and function with a
 // command line interface. It supercedes AddVerb(),
 // and AddAction(). Verbs can have their own options and
 // documentation.
 func (c *Cli) NewVerb(name string, usage string, fn func(io.Reader, io.Writer, io.Writer, []string, *flag.FlagSet) int) *Verb {
 	verb := NewVerb(name, usage, fn)
+	verb.parent = c
 	c.verbs[name] = verb
 	return verb
 }
@@ -499,9 +765,15 @@ func (c *Cli) Run(args []string) int {
 	key = strings.TrimSpace(key)
 	verb, ok := c.verbs[key]
 	if ok == false {
-		fmt.Fprintf(c.Eout, "do not known how to %q\n", key)
+		verbs := []string{}
+		for name := range c.verbs {
+			verbs = append(verbs, name)
+		}
+		fmt.Fprintf(c.Eout, "do not known how to %q%s\n", key, didYouMean(suggest(key, verbs)))
 		return 1
 	}
+	// NOTE: warnings from parsing verb options go to c.Eout
+	verb.FlagSet.SetOutput(c.Eout)
 	return verb.Fn(c.In, c.Out, c.Eout, restOfArgs, verb.FlagSet)
 }
 
@@ -21,12 +21,17 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"path"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -332,3 +337,1473 @@ func TestREMidUnderscoreMethod(t *testing.T) {
 		}
 	}
 }
+
+func TestCliFlagSet(t *testing.T) {
+	var (
+		name1, name2 string
+	)
+	app1 := NewCli(Version)
+	app2 := NewCli(Version)
+	if app1.FlagSet == nil || app2.FlagSet == nil {
+		t.Errorf("expected each Cli to have a FlagSet")
+		t.FailNow()
+	}
+	if app1.FlagSet == app2.FlagSet {
+		t.Errorf("expected each Cli to have its own FlagSet")
+		t.FailNow()
+	}
+	// Defining the same option on both should not panic
+	app1.StringVar(&name1, "n,name", "one", "set the name")
+	app2.StringVar(&name2, "n,name", "two", "set the name")
+
+	if err := app1.FlagSet.Parse([]string{"-name", "jane.doe", "a", "b"}); err != nil {
+		t.Errorf("app1 parse failed, %s", err)
+		t.FailNow()
+	}
+	if name1 != "jane.doe" {
+		t.Errorf("expected %q, got %q", "jane.doe", name1)
+	}
+	if name2 != "two" {
+		t.Errorf("expected %q, got %q", "two", name2)
+	}
+	if app1.NArg() != 2 {
+		t.Errorf("expected 2 args for app1, got %d", app1.NArg())
+	}
+	if app2.NArg() != 0 {
+		t.Errorf("expected 0 args for app2, got %d", app2.NArg())
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	var (
+		output string
+		count  int
+		quiet  bool
+	)
+	app := NewCli(Version)
+	app.StringVar(&output, "o,output", "", "output file name")
+	app.IntVar(&count, "c,count", 0, "count")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+
+	err := app.ParseArgs([]string{"-o", "out.json", "--count=3", "-quiet", "a.json", "-x"})
+	if err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+		t.FailNow()
+	}
+	if output != "out.json" {
+		t.Errorf("expected %q, got %q", "out.json", output)
+	}
+	if count != 3 {
+		t.Errorf("expected 3, got %d", count)
+	}
+	if quiet == false {
+		t.Errorf("expected quiet to be true")
+	}
+	args := app.Args()
+	if len(args) != 2 || args[0] != "a.json" || args[1] != "-x" {
+		t.Errorf("expected [a.json -x], got %+v", args)
+	}
+
+	checkKind := func(args []string, kind ParseErrorKind, option string) {
+		app := NewCli(Version)
+		app.StringVar(&output, "o,output", "", "output file name")
+		app.IntVar(&count, "c,count", 0, "count")
+		err := app.ParseArgs(args)
+		if err == nil {
+			t.Errorf("%+v: expected an error", args)
+			return
+		}
+		var pErr *ParseError
+		if errors.As(err, &pErr) == false {
+			t.Errorf("%+v: expected a *ParseError, got %T %s", args, err, err)
+			return
+		}
+		if pErr.Kind != kind {
+			t.Errorf("%+v: expected %s, got %s", args, kind, pErr.Kind)
+		}
+		if pErr.Option != option {
+			t.Errorf("%+v: expected option %q, got %q", args, option, pErr.Option)
+		}
+	}
+	checkKind([]string{"-ouptut", "out.json"}, UnknownOption, "-ouptut")
+	checkKind([]string{"-count", "three"}, InvalidValue, "-count")
+	checkKind([]string{"-c=three"}, InvalidValue, "-c")
+	checkKind([]string{"--output"}, MissingArgument, "--output")
+
+	app = NewCli(Version)
+	if err := app.ParseArgs([]string{"-help"}); err != flag.ErrHelp {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestGNUStyle(t *testing.T) {
+	var (
+		output string
+		count  int
+		quiet  bool
+		pretty bool
+		help   bool
+	)
+	app := NewCli(Version)
+	app.GNUStyle = true
+	app.StringVar(&output, "o,output", "", "output file name")
+	app.IntVar(&count, "c,count", 0, "count")
+	app.BoolVar(&quiet, "q,quiet", false, "suppress error messages")
+	app.BoolVar(&pretty, "p,pretty", false, "pretty print output")
+	app.BoolVar(&help, "help", false, "display help")
+
+	err := app.ParseArgs([]string{"-qp", "--output=out.json", "-c3", "-help", "--", "-x"})
+	if err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+		t.FailNow()
+	}
+	if quiet == false || pretty == false || help == false {
+		t.Errorf("expected quiet, pretty and help to be true, got %t, %t, %t", quiet, pretty, help)
+	}
+	if output != "out.json" {
+		t.Errorf("expected %q, got %q", "out.json", output)
+	}
+	if count != 3 {
+		t.Errorf("expected 3, got %d", count)
+	}
+	args := app.Args()
+	if len(args) != 1 || args[0] != "-x" {
+		t.Errorf("expected [-x], got %+v", args)
+	}
+
+	app = NewCli(Version)
+	app.GNUStyle = true
+	app.StringVar(&output, "o,output", "", "output file name")
+	app.BoolVar(&quiet, "q,quiet", false, "suppress error messages")
+	if err := app.ParseArgs([]string{"-qo", "out2.json", "--output", "out3.json"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if output != "out3.json" {
+		t.Errorf("expected %q, got %q", "out3.json", output)
+	}
+	err = app.ParseArgs([]string{"-qx"})
+	if err == nil || err.Error() != `"-x" is an unsupported option` {
+		t.Errorf("expected -x to be unsupported, got %v", err)
+	}
+
+	if _, ok := app.Options()["-o, --output"]; ok == false {
+		t.Errorf("expected %q in %+v", "-o, --output", app.Options())
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-q, --quiet")) == false {
+		t.Errorf("expected Usage to include %q, got\n%s", "-q, --quiet", buf.Bytes())
+	}
+	buf.Reset()
+	app.GenerateMarkdown(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-o, --output")) == false {
+		t.Errorf("expected GenerateMarkdown to include %q, got\n%s", "-o, --output", buf.Bytes())
+	}
+	buf.Reset()
+	app.GenerateManPage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-o, --output")) == false {
+		t.Errorf("expected GenerateManPage to include %q, got\n%s", "-o, --output", buf.Bytes())
+	}
+}
+
+// doiValue is a custom flag.Value used to test Var()
+type doiValue string
+
+func (d *doiValue) String() string { return string(*d) }
+
+func (d *doiValue) Set(s string) error {
+	if strings.HasPrefix(s, "10.") == false {
+		return fmt.Errorf("%q is not a DOI", s)
+	}
+	*d = doiValue(s)
+	return nil
+}
+
+func TestVarAndFunc(t *testing.T) {
+	var (
+		doi  doiValue
+		seen []string
+	)
+	app := NewCli(Version)
+	app.Var(&doi, "d,doi", "set the DOI")
+	app.Func("orcid", "add an ORCID", func(s string) error {
+		seen = append(seen, s)
+		return nil
+	})
+	if gotS := app.Option("doi"); gotS != "set the DOI" {
+		t.Errorf("expected %q, got %q", "set the DOI", gotS)
+	}
+	err := app.ParseArgs([]string{"-d", "10.22002/D1.1234", "-orcid", "0000-0001", "-orcid=0000-0002"})
+	if err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+		t.FailNow()
+	}
+	if doi != "10.22002/D1.1234" {
+		t.Errorf("expected %q, got %q", "10.22002/D1.1234", doi)
+	}
+	if len(seen) != 2 || seen[0] != "0000-0001" || seen[1] != "0000-0002" {
+		t.Errorf("expected two ORCID, got %+v", seen)
+	}
+	err = app.ParseArgs([]string{"-doi", "not-a-doi"})
+	var pErr *ParseError
+	if errors.As(err, &pErr) == false || pErr.Kind != InvalidValue {
+		t.Errorf("expected an InvalidValue error, got %v", err)
+	}
+}
+
+func TestSliceVar(t *testing.T) {
+	var (
+		inputs []string
+		fields []string
+		years  []int
+	)
+	app := NewCli(Version)
+	app.StringSliceVar(&inputs, "i,input", []string{"-"}, "", "input file name")
+	app.StringSliceVar(&fields, "fields", []string{"title", "doi"}, ",", "fields to include")
+	app.IntSliceVar(&years, "y,year", nil, ",", "years to harvest")
+	if len(fields) != 2 {
+		t.Errorf("expected default fields, got %+v", fields)
+	}
+	err := app.ParseArgs([]string{"-i", "a.json", "-i", "b.json", "-fields", "title, author", "-fields=doi", "-y", "2020,2021", "-y", "2022"})
+	if err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+		t.FailNow()
+	}
+	if strings.Join(inputs, " ") != "a.json b.json" {
+		t.Errorf("expected [a.json b.json], got %+v", inputs)
+	}
+	if strings.Join(fields, " ") != "title author doi" {
+		t.Errorf("expected [title author doi], got %+v", fields)
+	}
+	if len(years) != 3 || years[0] != 2020 || years[2] != 2022 {
+		t.Errorf("expected [2020 2021 2022], got %+v", years)
+	}
+	if err := app.ParseArgs([]string{"-y", "2020,twenty"}); err == nil {
+		t.Errorf("expected an error for an invalid int list")
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	expectedS := `fields to include (repeatable, values separated by ",") (default "title,doi")`
+	if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+		t.Errorf("expected Usage to include %q, got\n%s", expectedS, buf.Bytes())
+	}
+}
+
+func TestChoiceVar(t *testing.T) {
+	var format string
+	app := NewCli(Version)
+	app.ChoiceVar(&format, "f,format", "json", []string{"json", "csv", "tsv"}, "output format")
+	if format != "json" {
+		t.Errorf("expected default %q, got %q", "json", format)
+	}
+	if err := app.ParseArgs([]string{"-format", "csv"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if format != "csv" {
+		t.Errorf("expected %q, got %q", "csv", format)
+	}
+	err := app.ParseArgs([]string{"-f", "xml"})
+	if err == nil {
+		t.Errorf("expected an error for -f xml")
+		t.FailNow()
+	}
+	expectedS := `invalid value "xml" for "-f", must be one of json, csv, tsv`
+	if err.Error() != expectedS {
+		t.Errorf("expected %q, got %q", expectedS, err)
+	}
+	if format != "csv" {
+		t.Errorf("expected format to remain %q, got %q", "csv", format)
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.GenerateManPage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("output format (one of json|csv|tsv)")) == false {
+		t.Errorf("expected choices in man page, got\n%s", buf.Bytes())
+	}
+
+	defer func() {
+		if r := recover(); r == nil || strings.Contains(fmt.Sprintf("%v", r), `default "xml"`) == false {
+			t.Errorf("expected ChoiceVar() to panic for default xml, got %v", r)
+		}
+	}()
+	app = NewCli(Version)
+	app.ChoiceVar(&format, "format", "xml", []string{"json", "csv"}, "output format")
+}
+
+func TestRequired(t *testing.T) {
+	var (
+		dataset, key, output string
+		showHelp             bool
+	)
+	app := NewCli(Version)
+	app.StringVar(&dataset, "d,dataset", "", "dataset collection name")
+	app.StringVar(&key, "k,key", "", "record key")
+	app.StringVar(&output, "o,output", "", "output file name")
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.EnvStringVar(&dataset, "CLI_TEST_REQUIRED", "", "a required environment variable")
+	if err := app.Required("dataset", "k"); err != nil {
+		t.Errorf("expected Required() to succeed, %s", err)
+	}
+	if err := app.Required("not-defined"); err == nil {
+		t.Errorf("expected Required() to fail for an unknown option")
+	}
+	if err := app.RequiredEnv("CLI_TEST_REQUIRED"); err != nil {
+		t.Errorf("expected RequiredEnv() to succeed, %s", err)
+	}
+	os.Unsetenv("CLI_TEST_REQUIRED")
+
+	err := app.ParseArgs([]string{"-o", "out.json"})
+	var pErr *ParseError
+	if errors.As(err, &pErr) == false || pErr.Kind != MissingRequired {
+		t.Errorf("expected MissingRequired, got %v", err)
+		t.FailNow()
+	}
+	expectedS := "missing required -dataset, -key, CLI_TEST_REQUIRED"
+	if err.Error() != expectedS {
+		t.Errorf("expected %q, got %q", expectedS, err)
+	}
+
+	os.Setenv("CLI_TEST_REQUIRED", "yes")
+	defer os.Unsetenv("CLI_TEST_REQUIRED")
+	app = NewCli(Version)
+	app.StringVar(&dataset, "d,dataset", "", "dataset collection name")
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.Required("dataset")
+	if err := app.ParseArgs([]string{"-d", "test.ds"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	app = NewCli(Version)
+	app.StringVar(&dataset, "d,dataset", "", "dataset collection name")
+	app.AddStandardOptions()
+	app.Required("dataset")
+	if err := app.ParseArgs([]string{"-help"}); err != nil {
+		t.Errorf("expected -help to skip required options, %s", err)
+	}
+
+	// NOTE: only the standard options request information, not their names
+	var host string
+	app = NewCli(Version)
+	app.StringVar(&dataset, "d,dataset", "", "dataset collection name")
+	app.StringVar(&host, "h,host", "", "host name")
+	app.BoolVar(&showHelp, "version", false, "an application option")
+	app.Required("dataset")
+	for _, args := range [][]string{{"-h", "example.org"}, {"-version"}} {
+		err := app.ParseArgs(args)
+		if pe, ok := err.(*ParseError); ok == false || pe.Kind != MissingRequired {
+			t.Errorf("expected MissingRequired for %+v, got %v", args, err)
+		}
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("dataset collection name (required)")) == false {
+		t.Errorf("expected Usage to show required option, got\n%s", buf.Bytes())
+	}
+}
+
+func TestOptionDefaults(t *testing.T) {
+	var (
+		output  string
+		count   int
+		timeout time.Duration
+		quiet   bool
+	)
+	app := NewCli(Version)
+	app.StringVar(&output, "o,output", "out.json", "write output to `FILE`")
+	app.IntVar(&count, "c,count", 0, "number of records")
+	app.DurationVar(&timeout, "timeout", 30*time.Second, "request timeout")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	if gotS := app.Option("output"); gotS != "write output to `FILE`" {
+		t.Errorf("expected Option() to return usage as given, got %q", gotS)
+	}
+	options := app.Options()
+	if gotS := options["-o, -output"]; gotS != "write output to `FILE`" {
+		t.Errorf("expected Options() to keep the usage as given, got %q", gotS)
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	for _, expectedS := range []string{
+		`-o, -output FILE`, `write output to FILE (default "out.json")`,
+		`-c, -count INT`, "number of records\n",
+		`-timeout DURATION`, "request timeout (default 30s)",
+		"suppress error messages\n",
+	} {
+		if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+			t.Errorf("expected %q in usage, got\n%s", expectedS, buf.Bytes())
+		}
+	}
+	buf.Reset()
+	app.GenerateMarkdown(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-o, -output FILE")) == false {
+		t.Errorf("expected placeholder in markdown, got\n%s", buf.Bytes())
+	}
+}
+
+func TestLookupOption(t *testing.T) {
+	var (
+		output  string
+		noColor bool
+		format  string
+		fields  []string
+	)
+	app := NewCli(Version)
+	app.StringVar(&output, "output", "out.json", "output file name")
+	app.BoolVar(&noColor, "no-color", false, "disable color")
+	app.ChoiceVar(&format, "f,format", "json", []string{"json", "csv"}, "output format")
+	app.StringSliceVar(&fields, "fields", nil, ",", "fields to include")
+
+	if gotS := app.Option("o"); gotS != `"o" is an unsupported option` {
+		t.Errorf("expected -o to be unsupported, got %q", gotS)
+	}
+	if o := app.LookupOption("color"); o != nil {
+		t.Errorf("expected nil for color, got %+v", o)
+	}
+	o := app.LookupOption("-output")
+	if o == nil {
+		t.Errorf("expected to find -output")
+		t.FailNow()
+	}
+	if o.Type != "string" || o.Default != "out.json" || o.Usage != "output file name" || o.Order != 0 {
+		t.Errorf("unexpected option %+v", o)
+	}
+	o = app.LookupOption("f")
+	if o == nil || o.Type != "choice" || strings.Join(o.Choices(), ",") != "json,csv" {
+		t.Errorf("unexpected option %+v", o)
+	}
+	if err := app.ParseArgs([]string{"-format", "csv"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if o.String() != "csv" || o.Default != "json" {
+		t.Errorf("expected current value csv and default json, got %q and %q", o.String(), o.Default)
+	}
+	options := app.OptionList()
+	if len(options) != 4 {
+		t.Errorf("expected 4 options, got %d", len(options))
+		t.FailNow()
+	}
+	for i, name := range []string{"output", "no-color", "f", "fields"} {
+		if options[i].Names[0] != name || options[i].Order != i {
+			t.Errorf("expected %q at %d, got %+v", name, i, options[i])
+		}
+	}
+	if options[3].Type != "[]string" {
+		t.Errorf("expected []string, got %q", options[3].Type)
+	}
+	o = app.LookupOption("output")
+	o.Placeholder = "FILE"
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-output FILE")) == false {
+		t.Errorf("expected placeholder FILE, got\n%s", buf.Bytes())
+	}
+}
+
+func TestOptionGroups(t *testing.T) {
+	var (
+		showHelp, showVersion bool
+		dataset, key          string
+	)
+	app := NewCli(Version)
+	app.OptionGroup("Standard Options")
+	app.BoolVar(&showVersion, "version", false, "display version")
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.OptionGroup("Application Options")
+	app.StringVar(&key, "k,key", "", "record key")
+	app.StringVar(&dataset, "d,dataset", "", "dataset collection name")
+
+	if o := app.LookupOption("key"); o == nil || o.Group != "Application Options" {
+		t.Errorf("expected key in Application Options, got %+v", o)
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	src := buf.String()
+	positions := []int{}
+	for _, s := range []string{"Standard Options", "-h, -help", "-version", "Application Options", "-d, -dataset", "-k, -key"} {
+		pos := strings.Index(src, s)
+		if pos < 0 {
+			t.Errorf("expected %q in Usage, got\n%s", s, src)
+			t.FailNow()
+		}
+		positions = append(positions, pos)
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i-1] > positions[i] {
+			t.Errorf("options out of order in Usage\n%s", src)
+			break
+		}
+	}
+	buf.Reset()
+	app.GenerateMarkdown(buf)
+	if bytes.Contains(buf.Bytes(), []byte("### Application Options")) == false {
+		t.Errorf("expected group heading in markdown, got\n%s", buf.Bytes())
+	}
+	buf.Reset()
+	app.GenerateManPage(buf)
+	if bytes.Contains(buf.Bytes(), []byte(".SS Standard Options")) == false {
+		t.Errorf("expected group heading in man page, got\n%s", buf.Bytes())
+	}
+}
+
+func TestHiddenAndDeprecated(t *testing.T) {
+	var (
+		packageName string
+		debug       bool
+	)
+	app := NewCli(Version)
+	app.StringVar(&packageName, "p", "", "package name")
+	app.StringVar(&packageName, "package", "", "package name")
+	app.BoolVar(&debug, "debug", false, "show debug output")
+	if err := app.Hide("debug"); err != nil {
+		t.Errorf("expected Hide() to succeed, %s", err)
+	}
+	if err := app.Deprecate("p", "package"); err != nil {
+		t.Errorf("expected Deprecate() to succeed, %s", err)
+	}
+	if err := app.Deprecate("x", "package"); err == nil {
+		t.Errorf("expected Deprecate() to fail for an unknown option")
+	}
+
+	eout, err := os.CreateTemp("", "eout")
+	if err != nil {
+		t.Errorf("can't create temp file, %s", err)
+		t.FailNow()
+	}
+	defer os.Remove(eout.Name())
+	app.Eout = eout
+	if err := app.ParseArgs([]string{"-debug", "-p", "mypkg"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	eout.Close()
+	if debug == false || packageName != "mypkg" {
+		t.Errorf("expected hidden and deprecated options to be set, got %t, %q", debug, packageName)
+	}
+	src, _ := os.ReadFile(eout.Name())
+	expectedS := `WARNING: "-p" is deprecated, use -package instead`
+	if strings.TrimSpace(string(src)) != expectedS {
+		t.Errorf("expected %q, got %q", expectedS, src)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	if bytes.Contains(buf.Bytes(), []byte("-debug")) {
+		t.Errorf("expected -debug to be hidden, got\n%s", buf.Bytes())
+	}
+	if bytes.Contains(buf.Bytes(), []byte("Deprecated: -p (use -package instead)")) == false {
+		t.Errorf("expected deprecated note, got\n%s", buf.Bytes())
+	}
+	if _, ok := app.Options()["-debug"]; ok == true {
+		t.Errorf("expected -debug to be left out of Options()")
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	var (
+		markdown, manpage, verbose, debug bool
+		input, output, format             string
+	)
+	newApp := func() *Cli {
+		markdown, manpage, verbose, debug = false, false, false, false
+		input, output, format = "", "", "text"
+		app := NewCli("testcli")
+		app.BoolVar(&markdown, "generate-markdown", false, "generate Markdown")
+		app.BoolVar(&manpage, "generate-manpage", false, "generate a man page")
+		app.BoolVar(&verbose, "verbose", false, "verbose output")
+		app.BoolVar(&debug, "debug", false, "debug output")
+		app.StringVar(&input, "i,input", "", "input filename")
+		app.StringVar(&output, "o,output", "", "output filename")
+		app.ChoiceVar(&format, "format", "text", []string{"text", "json"}, "output format")
+		if err := app.MutuallyExclusive("generate-markdown", "generate-manpage"); err != nil {
+			t.Errorf("expected MutuallyExclusive() to succeed, %s", err)
+		}
+		if err := app.Requires("o", "input"); err != nil {
+			t.Errorf("expected Requires() to succeed, %s", err)
+		}
+		if err := app.Implies("debug", "verbose", "format=json"); err != nil {
+			t.Errorf("expected Implies() to succeed, %s", err)
+		}
+		return app
+	}
+
+	app := newApp()
+	if err := app.MutuallyExclusive("generate-markdown", "nothing"); err == nil {
+		t.Errorf("expected MutuallyExclusive() to fail for an unknown option")
+	}
+	if err := app.AtLeastOneOf("verbose"); err == nil {
+		t.Errorf("expected AtLeastOneOf() to fail for a single option")
+	}
+
+	err := app.ParseArgs([]string{"-generate-markdown", "-generate-manpage"})
+	pe, ok := err.(*ParseError)
+	if ok == false || pe.Kind != ConstraintViolation {
+		t.Errorf("expected a ConstraintViolation, got %T %v", err, err)
+	} else if expectedS := "-generate-markdown and -generate-manpage are mutually exclusive"; pe.Error() != expectedS {
+		t.Errorf("expected %q, got %q", expectedS, pe.Error())
+	}
+
+	app = newApp()
+	err = app.ParseArgs([]string{"-o", "out.txt"})
+	if err == nil || err.Error() != "-output requires -input" {
+		t.Errorf("expected %q, got %v", "-output requires -input", err)
+	}
+
+	app = newApp()
+	if err := app.ParseArgs([]string{"-o", "out.txt", "-i", "in.txt"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+
+	app = newApp()
+	if err := app.ParseArgs([]string{"-debug"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if verbose == false || format != "json" {
+		t.Errorf("expected -debug to imply -verbose and -format=json, got %t, %q", verbose, format)
+	}
+
+	app = newApp()
+	if err := app.ParseArgs([]string{"-debug", "-format", "text"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if format != "text" {
+		t.Errorf("expected explicit -format to win, got %q", format)
+	}
+
+	app = newApp()
+	app.AtLeastOneOf("input", "output")
+	err = app.ParseArgs([]string{})
+	if err == nil || err.Error() != "at least one of -input, -output is required" {
+		t.Errorf("expected at least one of error, got %v", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	for _, expectedS := range []string{
+		"-generate-markdown and -generate-manpage are mutually exclusive",
+		"-output requires -input",
+		"-debug implies -verbose and -format=json",
+	} {
+		if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+			t.Errorf("expected %q in usage, got\n%s", expectedS, buf.Bytes())
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	app := NewCli("testcli")
+	err := app.AddParams(
+		Param{Name: "COUNT", Type: "int", Usage: "number of copies", Required: true},
+		Param{Name: "VARIABLE_NAME", Required: true, Repeat: true},
+		Param{Name: "DIR", Required: true, Repeat: true},
+	)
+	if err != nil {
+		t.Errorf("expected AddParams() to succeed, %s", err)
+		t.FailNow()
+	}
+	if err := app.AddParams(Param{Name: "EXTRA"}); err == nil {
+		t.Errorf("expected AddParams() to fail for a parameter after a repeating group")
+	}
+	if err := NewCli("testcli").AddParams(Param{Name: "N", Type: "complex"}); err == nil {
+		t.Errorf("expected AddParams() to fail for an unsupported type")
+	}
+	if err := NewCli("testcli").AddParams(Param{Name: "A"}, Param{Name: "B", Required: true}); err == nil {
+		t.Errorf("expected AddParams() to fail for a required after an optional parameter")
+	}
+
+	if err := app.ParseArgs([]string{"2", "Assets", "htdocs", "Templates", "templates"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if count, ok := app.ParamValue("COUNT").(int); ok == false || count != 2 {
+		t.Errorf("expected COUNT to be int 2, got %T %v", app.ParamValue("COUNT"), app.ParamValue("COUNT"))
+	}
+	if names := app.ParamValues("VARIABLE_NAME"); len(names) != 2 || names[1] != "Templates" {
+		t.Errorf("expected two variable names, got %v", names)
+	}
+	if dirs := app.ParamValues("DIR"); len(dirs) != 2 || dirs[0] != "htdocs" {
+		t.Errorf("expected two dirs, got %v", dirs)
+	}
+
+	for args, expectedS := range map[string]string{
+		"":                  "missing parameter COUNT",
+		"2":                 "missing parameter VARIABLE_NAME, DIR",
+		"2 Assets":          "missing parameter DIR",
+		"two Assets htdocs": `invalid value "two" for "COUNT", parse error`,
+	} {
+		err := app.ParseArgs(strings.Fields(args))
+		if err == nil || err.Error() != expectedS {
+			t.Errorf("expected %q for %q, got %v", expectedS, args, err)
+		}
+	}
+
+	optional := NewCli("testcli")
+	optional.AddParams(Param{Name: "FILENAME", Required: true}, Param{Name: "URL"})
+	err = optional.ParseArgs([]string{"a.txt", "http://example.edu", "b.txt"})
+	if pe, ok := err.(*ParseError); ok == false || pe.Kind != UnexpectedParameter || pe.Value != "b.txt" {
+		t.Errorf("expected unexpected parameter b.txt, got %v", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	expectedS := "USAGE: " + app.AppName() + " COUNT VARIABLE_NAME DIR [VARIABLE_NAME DIR ...]"
+	if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+		t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+	}
+	buf = bytes.NewBuffer([]byte{})
+	optional.GenerateManPage(buf)
+	expectedS = ".SH SYNOPSIS\n\\fB" + optional.AppName() + "\\fP FILENAME [URL]\n"
+	if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+		t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+	}
+}
+
+func TestInterspersed(t *testing.T) {
+	var (
+		output string
+		quiet  bool
+	)
+	app := NewCli("testcli")
+	app.StringVar(&output, "o,output", "", "output filename")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	if err := app.ParseArgs([]string{"input.json", "-o", "out.json"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if output != "" || app.NArg() != 3 {
+		t.Errorf("expected options after input.json to be positional, got %q, %+v", output, app.Args())
+	}
+
+	app = NewCli("testcli")
+	app.Interspersed = true
+	app.StringVar(&output, "o,output", "", "output filename")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.NewVerb("list", "list items", nil)
+	if err := app.ParseArgs([]string{"input.json", "-o", "out.json", "more.json", "-quiet", "--", "-x"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if output != "out.json" || quiet == false {
+		t.Errorf("expected -o and -quiet to be set, got %q, %t", output, quiet)
+	}
+	if strings.Join(app.Args(), " ") != "input.json more.json -x" {
+		t.Errorf("expected [input.json more.json -x], got %+v", app.Args())
+	}
+
+	// Options after a verb are left for the verb to parse
+	quiet = false
+	if err := app.ParseArgs([]string{"list", "-quiet"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if quiet == true || strings.Join(app.Args(), " ") != "list -quiet" {
+		t.Errorf("expected [list -quiet], got %t, %+v", quiet, app.Args())
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	var (
+		output, format string
+		debug          bool
+	)
+	app := NewCli("testcli")
+	app.StringVar(&output, "o,output", "", "output filename")
+	app.StringVar(&format, "format", "", "output format")
+	app.BoolVar(&debug, "debug", false, "debug output")
+	app.Hide("debug")
+	for arg, expectedS := range map[string]string{
+		"-ouptut": `"-ouptut" is an unsupported option, did you mean -output?`,
+		"-form":   `"-form" is an unsupported option, did you mean -format?`,
+		"-debgu":  `"-debgu" is an unsupported option`,
+		"-zzz":    `"-zzz" is an unsupported option`,
+	} {
+		err := app.ParseArgs([]string{arg})
+		if err == nil || err.Error() != expectedS {
+			t.Errorf("expected %q, got %v", expectedS, err)
+		}
+	}
+
+	eout, err := os.CreateTemp("", "eout")
+	if err != nil {
+		t.Errorf("can't create temp file, %s", err)
+		t.FailNow()
+	}
+	defer os.Remove(eout.Name())
+	app.Eout = eout
+	app.NewVerb("list", "list items", nil)
+	app.NewVerb("lint", "lint items", nil)
+	if exitCode := app.Run([]string{"lsit"}); exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+	eout.Close()
+	src, _ := os.ReadFile(eout.Name())
+	expectedS := "do not known how to \"lsit\", did you mean list or lint?\n"
+	if s := string(src); s != expectedS {
+		t.Errorf("expected %q, got %q", expectedS, s)
+	}
+}
+
+func TestValueSource(t *testing.T) {
+	var (
+		output, format string
+		verbose        bool
+	)
+	os.Setenv("TEST_SOURCE_DATASET", "mydata")
+	defer os.Unsetenv("TEST_SOURCE_DATASET")
+	app := NewCli("testcli")
+	app.StringVar(&output, "o,output", "", "output filename")
+	app.StringVar(&format, "format", "text", "output format")
+	app.BoolVar(&verbose, "verbose", false, "verbose output")
+	app.EnvString("TEST_SOURCE_DATASET", "", "dataset name")
+	app.EnvString("TEST_SOURCE_UNSET", "default.ds", "an unset variable")
+	app.Implies("o", "verbose")
+	if err := app.ParseArgs([]string{"-o", "out.json"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	for name, expected := range map[string]Source{
+		"output":              SourceCommandLine,
+		"o":                   SourceCommandLine,
+		"verbose":             SourceCommandLine,
+		"format":              SourceDefault,
+		"TEST_SOURCE_DATASET": SourceEnvironment,
+		"TEST_SOURCE_UNSET":   SourceDefault,
+	} {
+		src, err := app.ValueSource(name)
+		if err != nil {
+			t.Errorf("expected ValueSource(%q) to succeed, %s", name, err)
+		}
+		if src != expected {
+			t.Errorf("expected %s for %q, got %s", expected, name, src)
+		}
+	}
+	if _, err := app.ValueSource("nothing"); err == nil {
+		t.Errorf("expected ValueSource() to fail for an unknown name")
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.ShowConfig(buf)
+	for _, expectedS := range []string{
+		`-output               "out.json" (command line, default "")`,
+		`-format               "text" (default)`,
+		`TEST_SOURCE_DATASET   "mydata" (environment, default "")`,
+	} {
+		if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+			t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+		}
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	var (
+		dataset, format string
+		limit           int
+		ids             []string
+	)
+	dir := t.TempDir()
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	os.Unsetenv("TEST_CONFIG_FILE")
+	os.Unsetenv("TEST_CONFIG_LIMIT")
+	defer os.Unsetenv("TEST_CONFIG_LIMIT")
+
+	newApp := func() *Cli {
+		dataset, format, limit, ids = "", "text", 10, nil
+		app := NewCli("testcli")
+		app.ConfigFile("config", "TEST_CONFIG_FILE", "testcli.ini")
+		app.StringVar(&dataset, "dataset", "", "dataset name")
+		app.StringVar(&format, "format", "text", "output format")
+		app.StringSliceVar(&ids, "id", []string{}, "", "identifiers")
+		app.IntVarEnv(&limit, "limit", "TEST_CONFIG_LIMIT", 10, "maximum records")
+		return app
+	}
+
+	// A missing default config file is not an error
+	app := newApp()
+	if err := app.ParseArgs([]string{}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if app.ConfigPath() != "" {
+		t.Errorf("expected no config file, got %q", app.ConfigPath())
+	}
+
+	iniName := path.Join(dir, app.AppName(), "testcli.ini")
+	os.MkdirAll(path.Dir(iniName), 0775)
+	os.WriteFile(iniName, []byte(`# settings
+[harvest]
+dataset = "config.ds"
+format = json
+limit = 20
+id = a
+id = b
+`), 0664)
+	os.Setenv("TEST_CONFIG_LIMIT", "30")
+	app = newApp()
+	if err := app.ParseArgs([]string{"-format", "csv", "-id", "c"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if app.ConfigPath() != iniName {
+		t.Errorf("expected %q, got %q", iniName, app.ConfigPath())
+	}
+	if dataset != "config.ds" || format != "csv" || limit != 30 || strings.Join(ids, ",") != "c" {
+		t.Errorf("expected config.ds, csv, 30, [c], got %q, %q, %d, %v", dataset, format, limit, ids)
+	}
+	for name, expected := range map[string]Source{
+		"dataset": SourceConfig,
+		"format":  SourceCommandLine,
+		"limit":   SourceEnvironment,
+	} {
+		if src, _ := app.ValueSource(name); src != expected {
+			t.Errorf("expected %s for %q, got %s", expected, name, src)
+		}
+	}
+
+	jsonName := path.Join(dir, "settings.json")
+	os.WriteFile(jsonName, []byte(`{"dataset": "json.ds", "limit": 40, "id": ["x", "y"]}`), 0664)
+	os.Unsetenv("TEST_CONFIG_LIMIT")
+	app = newApp()
+	if err := app.ParseArgs([]string{"-config", jsonName}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if dataset != "json.ds" || limit != 40 || strings.Join(ids, ",") != "x,y" {
+		t.Errorf("expected json.ds, 40, [x y], got %q, %d, %v", dataset, limit, ids)
+	}
+
+	os.WriteFile(jsonName, []byte(`{"colour": "red"}`), 0664)
+	app = newApp()
+	if err := app.ParseArgs([]string{"-config=" + jsonName}); err == nil {
+		t.Errorf("expected ParseArgs() to fail for an unsupported setting")
+	}
+	for _, src := range []string{`{"format": null}`, `{"format": {"name": "csv"}}`, `{"id": [["a"], "b"]}`} {
+		os.WriteFile(jsonName, []byte(src), 0664)
+		app = newApp()
+		err := app.ParseArgs([]string{"-config", jsonName})
+		if err == nil || strings.HasSuffix(err.Error(), "unsupported value") == false {
+			t.Errorf("expected an unsupported value error for %s, got %v", src, err)
+		}
+	}
+	app = newApp()
+	if err := app.ParseArgs([]string{"-config", path.Join(dir, "missing.json")}); err == nil {
+		t.Errorf("expected ParseArgs() to fail for a missing config file")
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	expectedS := "FILES\n\n    $XDG_CONFIG_HOME/" + app.AppName() + "/testcli.ini\n"
+	if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+		t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+	}
+}
+
+func TestConfigConstraints(t *testing.T) {
+	var (
+		dataset, format string
+		a, b, debug     bool
+	)
+	dir := t.TempDir()
+	newApp := func() *Cli {
+		dataset, format, a, b, debug = "", "text", false, false, false
+		app := NewCli("testcli")
+		app.ConfigFile("config", "", "")
+		app.StringVar(&dataset, "dataset", "", "dataset name")
+		app.StringVar(&format, "format", "text", "output format")
+		app.BoolVar(&a, "a", false, "option a")
+		app.BoolVar(&b, "b", false, "option b")
+		app.BoolVar(&debug, "debug", false, "debug output")
+		app.Required("dataset")
+		app.MutuallyExclusive("a", "b")
+		app.Implies("debug", "format=json")
+		return app
+	}
+
+	fName := path.Join(dir, "settings.json")
+	os.WriteFile(fName, []byte(`{"dataset": "y.ds", "debug": true}`), 0664)
+	app := newApp()
+	if err := app.ParseArgs([]string{"-config", fName}); err != nil {
+		t.Errorf("expected the config file to supply -dataset, %s", err)
+	}
+	if format != "json" {
+		t.Errorf("expected -debug from the config file to imply -format=json, got %q", format)
+	}
+	if src, _ := app.ValueSource("format"); src != SourceConfig {
+		t.Errorf("expected the implied -format to come from the config file, got %s", src)
+	}
+
+	os.WriteFile(fName, []byte(`{"dataset": "y.ds", "a": true, "b": true}`), 0664)
+	app = newApp()
+	err := app.ParseArgs([]string{"-config", fName})
+	if pe, ok := err.(*ParseError); ok == false || pe.Kind != ConstraintViolation {
+		t.Errorf("expected ConstraintViolation for -a and -b in the config file, got %v", err)
+	}
+	os.WriteFile(fName, []byte(`{"dataset": "y.ds", "a": true}`), 0664)
+	app = newApp()
+	err = app.ParseArgs([]string{"-config", fName, "-b"})
+	if pe, ok := err.(*ParseError); ok == false || pe.Kind != ConstraintViolation {
+		t.Errorf("expected ConstraintViolation for -a in the config file and -b, got %v", err)
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	var output string
+	var quiet bool
+	newApp := func(gnu bool) *Cli {
+		app := NewCli("testcli")
+		app.GNUStyle = gnu
+		app.ConfigFile("c,config", "", "")
+		app.StringVar(&output, "o,output", "", "output file")
+		app.BoolVar(&quiet, "q,quiet", false, "quiet")
+		app.NewVerb("run", "run the job", nil)
+		return app
+	}
+	for _, tc := range []struct {
+		gnu      bool
+		args     []string
+		expected string
+	}{
+		{false, []string{"-config", "a.json"}, "a.json"},
+		{false, []string{"-config=a.json", "input.txt"}, "a.json"},
+		{false, []string{"run", "-config", "a.json"}, ""},
+		{false, []string{"input.txt", "-config", "a.json"}, ""},
+		{false, []string{"-o", "-config", "a.json"}, ""},
+		{false, []string{"--", "-config", "a.json"}, ""},
+		{true, []string{"-qc", "a.json"}, "a.json"},
+		{true, []string{"-qca.json"}, "a.json"},
+		{true, []string{"--config=a.json"}, "a.json"},
+		{true, []string{"-oc", "-c", "a.json"}, "a.json"},
+		{true, []string{"-o", "--config", "a.json"}, ""},
+	} {
+		app := newApp(tc.gnu)
+		if fName, _ := app.configPath(tc.args); fName != tc.expected {
+			t.Errorf("expected %q for %+v, got %q", tc.expected, tc.args, fName)
+		}
+	}
+}
+
+func TestStandardOptions(t *testing.T) {
+	app := NewCli("v0.0.1")
+	std := app.AddStandardOptions("input")
+	if app.LookupOption("input") != nil || app.LookupOption("i") != nil {
+		t.Errorf("expected -input to be skipped")
+	}
+	if o := app.LookupOption("version"); o == nil || o.Group != "Standard Options" {
+		t.Errorf("expected -version in Standard Options, got %+v", o)
+	}
+
+	outName := path.Join(t.TempDir(), "version.txt")
+	if err := app.ParseArgs([]string{"-o", outName, "-version"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	exit, err := app.HandleStandardOptions()
+	if err != nil {
+		t.Errorf("expected HandleStandardOptions() to succeed, %s", err)
+	}
+	if exit == false || std.ShowVersion == false {
+		t.Errorf("expected -version to request exit")
+	}
+	if err := app.CloseStandardOptions(); err != nil {
+		t.Errorf("expected CloseStandardOptions() to succeed, %s", err)
+	}
+	src, _ := os.ReadFile(outName)
+	if strings.TrimSpace(string(src)) != app.Version() {
+		t.Errorf("expected %q, got %q", app.Version(), src)
+	}
+
+	app = NewCli("v0.0.1")
+	std = app.AddStandardOptions()
+	if err := app.ParseArgs([]string{"-quiet"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	exit, err = app.HandleStandardOptions()
+	if err != nil || exit == true || std.Quiet == false {
+		t.Errorf("expected to continue with -quiet set, got %t, %v", exit, err)
+	}
+	app.CloseStandardOptions()
+
+	for _, args := range [][]string{{"-show-config"}, {"-show-config=json"}} {
+		app = NewCli("v0.0.1")
+		std = app.AddStandardOptions()
+		outName = path.Join(t.TempDir(), "config.txt")
+		if err := app.ParseArgs(append(args, "-o", outName)); err != nil {
+			t.Errorf("expected ParseArgs() to succeed for %+v, %s", args, err)
+		}
+		if exit, err := app.HandleStandardOptions(); err != nil || exit == false || std.ShowConfig == false {
+			t.Errorf("expected %+v to request exit, got %t, %v", args, exit, err)
+		}
+		app.CloseStandardOptions()
+		src, _ = os.ReadFile(outName)
+		isJSON := bytes.HasPrefix(src, []byte("["))
+		if isJSON != (std.ConfigFormat == "json") || bytes.Contains(src, []byte("-show-config")) == (std.ConfigFormat == "json") {
+			t.Errorf("expected %s output for %+v, got\n%s", std.ConfigFormat, args, src)
+		}
+	}
+	app = NewCli("v0.0.1")
+	app.AddStandardOptions()
+	if err := app.ParseArgs([]string{"-show-config=yaml"}); err == nil {
+		t.Errorf("expected ParseArgs() to fail for -show-config=yaml")
+	}
+
+	// NOTE: skipping "v" frees it for a counter but keeps -version
+	var verbose int
+	app = NewCli("v0.0.1")
+	std = app.AddStandardOptions("v")
+	app.CountVar(&verbose, "v,verbose", 0, "raise verbosity")
+	if err := app.ParseArgs([]string{"-vv", "-version"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if verbose != 2 || std.ShowVersion == false {
+		t.Errorf("expected verbose 2 and -version, got %d, %t", verbose, std.ShowVersion)
+	}
+
+	if _, err := NewCli("v0.0.1").HandleStandardOptions(); err == nil {
+		t.Errorf("expected HandleStandardOptions() to fail without AddStandardOptions()")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2021, time.March, 10, 15, 30, 0, 0, time.UTC)
+	for s, expected := range map[string]time.Time{
+		"2021-01-02T03:04:05Z": time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC),
+		"2021-01-02":           time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC),
+		"2021-01-02 03:04:05":  time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC),
+		"now":                  now,
+		"today":                time.Date(2021, time.March, 10, 0, 0, 0, 0, time.UTC),
+		"yesterday":            time.Date(2021, time.March, 9, 0, 0, 0, 0, time.UTC),
+		"7d ago":               time.Date(2021, time.March, 3, 15, 30, 0, 0, time.UTC),
+		"2w ago":               time.Date(2021, time.February, 24, 15, 30, 0, 0, time.UTC),
+		"90m ago":              time.Date(2021, time.March, 10, 14, 0, 0, 0, time.UTC),
+	} {
+		result, err := parseTime(s, now)
+		if err != nil {
+			t.Errorf("expected %q to parse, %s", s, err)
+		} else if result.Equal(expected) == false {
+			t.Errorf("expected %s for %q, got %s", expected, s, result)
+		}
+	}
+	for _, s := range []string{"", "01/02/2021", "seven days ago", "-3d ago"} {
+		if _, err := parseTime(s, now); err == nil {
+			t.Errorf("expected %q to fail", s)
+		}
+	}
+}
+
+func TestTimeVar(t *testing.T) {
+	var since, until time.Time
+	app := NewCli("testcli")
+	app.TimeVar(&since, "since", time.Time{}, "harvest records changed since `TIMESTAMP`")
+	app.TimeVar(&until, "until", time.Time{}, "harvest records changed until")
+	if err := app.ParseArgs([]string{"-since", "2021-01-02", "-until", "1d ago"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if since.Format("2006-01-02") != "2021-01-02" {
+		t.Errorf("expected 2021-01-02, got %s", since)
+	}
+	if until.IsZero() || until.After(time.Now()) {
+		t.Errorf("expected until in the past, got %s", until)
+	}
+	err := app.ParseArgs([]string{"-since", "last tuesday"})
+	if pe, ok := err.(*ParseError); ok == false || pe.Kind != InvalidValue {
+		t.Errorf("expected an InvalidValue error, got %v", err)
+	}
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	if bytes.Contains(buf.Bytes(), []byte(`-until TIME`)) == false || bytes.Contains(buf.Bytes(), []byte(`"7d ago"`)) == false {
+		t.Errorf("expected accepted formats documented, got\n%s", buf.Bytes())
+	}
+}
+
+func TestPathVar(t *testing.T) {
+	var comment, assets, output string
+	dir := t.TempDir()
+	fName := path.Join(dir, "comment.txt")
+	os.WriteFile(fName, []byte("a comment\n"), 0664)
+	os.Setenv("TEST_PATH_DIR", dir)
+	defer os.Unsetenv("TEST_PATH_DIR")
+
+	app := NewCli("testcli")
+	app.FileVar(&comment, "comment", "", "comment file")
+	app.DirVar(&assets, "assets", "", "asset directory")
+	app.WritablePathVar(&output, "o,output", "", "output file")
+	app.AddParams(Param{Name: "DIR", Type: "dir", Required: true})
+	if err := app.ParseArgs([]string{"-comment", "$TEST_PATH_DIR/comment.txt", "-assets", "${TEST_PATH_DIR}", "-o", path.Join(dir, "out.go"), dir}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if comment != fName || assets != dir || output != path.Join(dir, "out.go") {
+		t.Errorf("expected expanded paths, got %q, %q, %q", comment, assets, output)
+	}
+	if app.ParamValue("DIR") != dir {
+		t.Errorf("expected DIR %q, got %v", dir, app.ParamValue("DIR"))
+	}
+	if _, err := os.Stat(path.Join(dir, "out.go")); err == nil {
+		t.Errorf("expected checking -o not to create the file")
+	}
+
+	for _, args := range [][]string{
+		{"-comment", path.Join(dir, "missing.txt"), dir},
+		{"-comment", dir, dir},
+		{"-assets", fName, dir},
+		{"-o", dir, dir},
+		{"-o", path.Join(dir, "missing", "out.go"), dir},
+		{path.Join(dir, "missing")},
+	} {
+		err := app.ParseArgs(args)
+		if pe, ok := err.(*ParseError); ok == false || pe.Kind != InvalidValue {
+			t.Errorf("expected InvalidValue for %+v, got %v", args, err)
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if s := ExpandPath("~/notes.txt"); s != path.Join(home, "notes.txt") {
+			t.Errorf("expected ~ expanded to %q, got %q", home, s)
+		}
+	}
+}
+
+func TestCountAndNegatable(t *testing.T) {
+	var verbose int
+	var color, quiet bool
+
+	app := NewCli("testcli")
+	app.CountVar(&verbose, "v,verbose", 0, "raise verbosity")
+	app.BoolVar(&color, "color", true, "colorize output")
+	app.BoolVar(&quiet, "q,quiet", false, "suppress messages")
+	if err := app.Negatable("color", "quiet"); err != nil {
+		t.Errorf("expected Negatable() to succeed, %s", err)
+	}
+	if err := app.Negatable("verbose"); err == nil {
+		t.Errorf("expected Negatable() to reject a counter option")
+	}
+	if err := app.Negatable("missing"); err == nil {
+		t.Errorf("expected Negatable() to reject an unknown option")
+	}
+
+	if err := app.ParseArgs([]string{"-v", "-verbose", "-vv", "-no-color"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if verbose != 4 || color != false {
+		t.Errorf("expected verbose 4 and color false, got %d, %t", verbose, color)
+	}
+	if src, _ := app.ValueSource("color"); src != SourceCommandLine {
+		t.Errorf("expected -no-color to set -color from the command line, got %s", src)
+	}
+	if err := app.ParseArgs([]string{"-v=2", "-quiet", "-no-quiet=false"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if verbose != 2 || quiet != true {
+		t.Errorf("expected verbose 2 and quiet true, got %d, %t", verbose, quiet)
+	}
+
+	var buf bytes.Buffer
+	app.Usage(&buf)
+	for _, s := range []string{"-[no-]color", "-q, -[no-]quiet", "(repeatable)"} {
+		if strings.Contains(buf.String(), s) == false {
+			t.Errorf("expected %q in usage, got %s", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "-no-color") {
+		t.Errorf("expected -no-color to be documented as -[no-]color, got %s", buf.String())
+	}
+
+	app = NewCli("testcli")
+	app.GNUStyle = true
+	app.CountVar(&verbose, "v,verbose", 0, "raise verbosity")
+	app.BoolVar(&color, "color", true, "colorize output")
+	app.Negatable("color")
+	if err := app.ParseArgs([]string{"-vvv", "--no-color"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if verbose != 3 || color != false {
+		t.Errorf("expected verbose 3 and color false, got %d, %t", verbose, color)
+	}
+	buf.Reset()
+	app.Usage(&buf)
+	if strings.Contains(buf.String(), "--[no-]color") == false {
+		t.Errorf("expected --[no-]color in usage, got %s", buf.String())
+	}
+}
+
+func TestDumpConfig(t *testing.T) {
+	var user, password string
+	os.Setenv("TEST_DUMP_TOKEN", "s3cr3t")
+	defer os.Unsetenv("TEST_DUMP_TOKEN")
+	os.Setenv("TEST_DUMP_DATASET", "mydata")
+	defer os.Unsetenv("TEST_DUMP_DATASET")
+	app := NewCli("testcli")
+	app.StringVar(&user, "user", "guest", "user name")
+	app.StringVar(&password, "password", "changeme", "the user's `PASSWORD`")
+	app.EnvString("TEST_DUMP_TOKEN", "", "API token")
+	app.EnvString("TEST_DUMP_DATASET", "default.ds", "dataset name")
+	if err := app.Secret("password", "TEST_DUMP_TOKEN"); err != nil {
+		t.Errorf("expected Secret() to succeed, %s", err)
+	}
+	if err := app.Secret("nothing"); err == nil {
+		t.Errorf("expected Secret() to fail for an unknown name")
+	}
+	if err := app.ParseArgs([]string{"-user", "jane", "-password", "hunter2"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := app.DumpConfig(buf, "text"); err != nil {
+		t.Errorf("expected DumpConfig() to succeed, %s", err)
+	}
+	for _, expectedS := range []string{
+		`-user               "jane" (command line, default "guest")`,
+		`-password           "********" (command line, default "********")`,
+		`TEST_DUMP_DATASET   "mydata" (environment, default "default.ds")`,
+		`TEST_DUMP_TOKEN     "********" (environment, default "")`,
+	} {
+		if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+			t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+		}
+	}
+
+	buf.Reset()
+	if err := app.DumpConfig(buf, "json"); err != nil {
+		t.Errorf("expected DumpConfig() to succeed, %s", err)
+	}
+	entries := []map[string]string{}
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Errorf("expected JSON, %s\n%s", err, buf.Bytes())
+	}
+	if len(entries) != 4 || entries[0]["name"] != "user" || entries[0]["default"] != "guest" || entries[0]["kind"] != "option" {
+		t.Errorf("expected the user option first, got %+v", entries)
+	}
+
+	for _, s := range []string{"hunter2", "changeme", "s3cr3t"} {
+		buf.Reset()
+		app.ShowConfig(buf)
+		app.DumpConfig(buf, "json")
+		app.Usage(buf)
+		if bytes.Contains(buf.Bytes(), []byte(s)) {
+			t.Errorf("expected %q to be redacted, got\n%s", s, buf.Bytes())
+		}
+	}
+	app = NewCli("testcli")
+	app.StringVarEnv(&user, "u,user", "TEST_DUMP_USER", "guest", "user name")
+	buf.Reset()
+	app.DumpConfig(buf, "text")
+	if strings.Count(buf.String(), "\n") != 1 || strings.HasPrefix(buf.String(), `-user, TEST_DUMP_USER   "guest" (default)`) == false {
+		t.Errorf("expected one -user, TEST_DUMP_USER entry, got\n%s", buf.String())
+	}
+	if err := app.DumpConfig(buf, "yaml"); err == nil {
+		t.Errorf("expected DumpConfig() to fail for an unsupported format")
+	}
+}
+
+func TestResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(path.Join(dir, "args.txt"), []byte(`# options for the nightly harvest
+-format json   # trailing comment
+-title 'Caltech Library' -note "say \"hi\" $HOME"
+@ids.txt
+`), 0664)
+	os.WriteFile(path.Join(dir, "ids.txt"), []byte("id1 id2 \\\nid3 ''\n"), 0664)
+	os.WriteFile(path.Join(dir, "loop.txt"), []byte("-format text @loop2.txt\n"), 0664)
+	os.WriteFile(path.Join(dir, "loop2.txt"), []byte("@loop.txt\n"), 0664)
+	os.WriteFile(path.Join(dir, "quote.txt"), []byte("-title 'unfinished\n"), 0664)
+
+	var format, title, note string
+	app := NewCli("testcli")
+	app.ResponseFiles = true
+	app.StringVar(&format, "format", "text", "output format")
+	app.StringVar(&title, "title", "", "title")
+	app.StringVar(&note, "note", "", "note")
+	if err := app.ParseArgs([]string{"@" + path.Join(dir, "args.txt"), "id4", "--", "@literal"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if format != "json" || title != "Caltech Library" || note != `say "hi" $HOME` {
+		t.Errorf("expected json, Caltech Library and the note, got %q, %q, %q", format, title, note)
+	}
+	expected := []string{"id1", "id2", "id3", "", "id4", "--", "@literal"}
+	if strings.Join(app.Args(), "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %+v, got %+v", expected, app.Args())
+	}
+
+	for _, fName := range []string{"loop.txt", "quote.txt", "missing.txt"} {
+		if err := app.ParseArgs([]string{"@" + path.Join(dir, fName)}); err == nil {
+			t.Errorf("expected ParseArgs() to fail for %s", fName)
+		}
+	}
+
+	// NOTE: Parse() reads the config file named in the response file
+	jsonName := path.Join(dir, "settings.json")
+	os.WriteFile(jsonName, []byte(`{"title": "From Config"}`), 0664)
+	os.WriteFile(path.Join(dir, "parse.txt"), []byte("-config settings.json -format csv\n"), 0664)
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{"testcli", "@" + path.Join(dir, "parse.txt")}
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+	app = NewCli("testcli")
+	app.ResponseFiles = true
+	app.ConfigFile("config", "", "")
+	app.StringVar(&format, "format", "text", "output format")
+	app.StringVar(&title, "title", "", "title")
+	if err := app.Parse(); err != nil || format != "csv" || title != "From Config" {
+		t.Errorf("expected csv and From Config, got %q, %q, %v", format, title, err)
+	}
+
+	app = NewCli("testcli")
+	app.StringVar(&format, "format", "text", "output format")
+	if err := app.ParseArgs([]string{"@" + path.Join(dir, "args.txt")}); err != nil || app.Arg(0) != "@"+path.Join(dir, "args.txt") {
+		t.Errorf("expected response files to be left alone by default, got %+v, %v", app.Args(), err)
+	}
+}
+
+func TestNegatableBools(t *testing.T) {
+	var color, cache, noCache, verbose bool
+	var level int
+	app := NewCli("testcli")
+	app.NegatableBools = true
+	app.BoolVar(&color, "c,color", true, "colorize output")
+	app.BoolVar(&cache, "cache", true, "cache results")
+	app.BoolVar(&noCache, "no-cache", false, "skip the cache")
+	app.BoolVar(&verbose, "V", false, "verbose output")
+	app.CountVar(&level, "level", 0, "raise the level")
+	if err := app.ParseArgs([]string{"-no-color", "-no-cache"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if color != false || cache != true || noCache != true {
+		t.Errorf("expected color false, cache true and no-cache true, got %t, %t, %t", color, cache, noCache)
+	}
+	for _, name := range []string{"no-V", "no-level", "no-no-cache"} {
+		if app.FlagSet.Lookup(name) != nil {
+			t.Errorf("expected no %q option", name)
+		}
+	}
+	if err := app.Negatable("cache"); err == nil {
+		t.Errorf("expected Negatable() to fail when -no-cache is already defined")
+	}
+
+	var buf bytes.Buffer
+	app.Usage(&buf)
+	if strings.Contains(buf.String(), "-c, -[no-]color") == false {
+		t.Errorf("expected -c, -[no-]color in usage, got %s", buf.String())
+	}
+
+	// NOTE: standard, hidden and deprecated options are not negated
+	var debug, old, quiet bool
+	app = NewCli("testcli")
+	app.NegatableBools = true
+	app.AddStandardOptions()
+	app.BoolVar(&color, "color", true, "colorize output")
+	app.BoolVar(&debug, "debug", false, "debug output")
+	app.BoolVar(&old, "old", false, "old behavior")
+	app.Hide("debug")
+	app.Deprecate("old", "")
+	buf.Reset()
+	app.Usage(&buf)
+	if strings.Count(buf.String(), "[no-]") != 1 || strings.Contains(buf.String(), "-[no-]color") == false {
+		t.Errorf("expected only -[no-]color to be negatable, got %s", buf.String())
+	}
+	for _, name := range []string{"no-help", "no-version", "no-show-config", "no-quiet", "no-debug", "no-old"} {
+		if app.FlagSet.Lookup(name) != nil {
+			t.Errorf("expected no %q option", name)
+		}
+	}
+
+	app = NewCli("testcli")
+	app.BoolVar(&quiet, "q", false, "suppress messages")
+	if err := app.Negatable("q"); err == nil {
+		t.Errorf("expected Negatable() to fail for a short only option")
+	}
+
+	app = NewCli("testcli")
+	app.NegatableBools = true
+	verb := app.NewVerb("harvest", "harvest records", nil)
+	verb.BoolVar(&color, "color", true, "colorize output")
+	if err := verb.Parse([]string{"-no-color"}); err != nil || color != false {
+		t.Errorf("expected the verb to inherit NegatableBools, got %t, %v", color, err)
+	}
+}
@@ -53,17 +53,8 @@ how to use the cli package.
 	bugs = `_cligenerator_ is only a proof of concept implementation`
 
 	// Standard Options
-	showHelp         bool
-	showLicense      bool
-	showVersion      bool
-	showExamples     bool
-	inputFName       string
-	outputFName      string
-	newLine          bool
-	quiet            bool
-	prettyPrint      bool
-	generateMarkdown bool
-	generateManPage  bool
+	newLine     bool
+	prettyPrint bool
 
 	// Application Options
 	appName             string
@@ -85,19 +76,13 @@ func main() {
 	app.AddHelp("examples", []byte(examples))
 
 	// Standard Options
-	app.BoolVar(&showHelp, "h,help", false, "display help")
-	app.BoolVar(&showLicense, "l,license", false, "display license")
-	app.BoolVar(&showVersion, "v,version", false, "display version")
-	app.BoolVar(&showExamples, "examples", false, "display examples")
-	app.StringVar(&inputFName, "i,input", "", "input file name")
-	app.StringVar(&outputFName, "o,output", "", "output file name")
+	std := app.AddStandardOptions()
+	app.OptionGroup("Standard Options")
 	app.BoolVar(&newLine, "nl,newline", false, "if true add a trailing newline")
-	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
 	app.BoolVar(&prettyPrint, "p,pretty", false, "pretty print output")
-	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate markdown documentation")
-	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
 
 	// Application Options
+	app.OptionGroup("Application Options")
 	app.StringVar(&appName, "app", "[YOUR APP NAME GOES HERE]", "set the name of your generated app (e.g. helloworld)")
 	app.StringVar(&appSynopsis, "synopsis", "[SHORT APP DESCRIPTION GOES HERE]", "set a short application synopsis (e.g. says 'Hello World!')")
 	app.StringVar(&appAuthor, "name,author", "[YOUR AUTHOR STRING GOES HERE]", "set the author name (e.g. '@author Jane Doe, <jane.doe@example.edu>')")
@@ -109,43 +94,13 @@ func main() {
 
 	// We're ready to process args
 	app.Parse()
-	args := app.Args()
-
-	// Setup IO
-	var err error
 
+	// Setup IO and handle the standard options
 	app.Eout = os.Stderr
-	app.In, err = cli.Open(inputFName, os.Stdin)
-	cli.ExitOnError(app.Eout, err, quiet)
-	defer cli.CloseFile(inputFName, app.In)
-
-	app.Out, err = cli.Create(outputFName, os.Stdout)
-	cli.ExitOnError(app.Eout, err, quiet)
-	defer cli.CloseFile(outputFName, app.Out)
-
-	// Handle options
-	if generateMarkdown {
-		app.GenerateMarkdown(app.Out)
-		os.Exit(0)
-	}
-	if generateManPage {
-		app.GenerateManPage(app.Out)
-		os.Exit(0)
-	}
-	if showHelp || showExamples {
-		if len(args) > 0 {
-			fmt.Fprintf(app.Out, app.Help(args...))
-		} else {
-			app.Usage(app.Out)
-		}
-		os.Exit(0)
-	}
-	if showLicense {
-		fmt.Fprintln(app.Out, app.License())
-		os.Exit(0)
-	}
-	if showVersion {
-		fmt.Fprintln(app.Out, app.Version())
+	exit, err := app.HandleStandardOptions()
+	cli.ExitOnError(app.Eout, err, std.Quiet)
+	defer app.CloseStandardOptions()
+	if exit {
 		os.Exit(0)
 	}
 
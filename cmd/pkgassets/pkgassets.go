@@ -65,15 +65,7 @@ name will be the name of the package plus the ".go" extension.
 `
 
 	// Standard Options
-	showHelp         bool
-	showLicense      bool
-	showVersion      bool
-	showExamples     bool
-	showVerbose      bool
-	outputFName      string
-	generateMarkdown bool
-	generateManPage  bool
-	quiet            bool
+	showVerbose bool
 
 	// App Options
 	packageName   string
@@ -97,8 +89,11 @@ func main() {
 	app := cli.NewCli(cli.Version)
 	appName := app.AppName()
 
-	// Document non-option parameters
-	app.SetParams("VARIABLE_NAME", "DIR_HOLDING_ASSETS", "[VARIABLE_NAME DIR_HOLDING_ASSETS ...]")
+	// Describe non-option parameters, these come in pairs
+	app.AddParams(
+		cli.Param{Name: "VARIABLE_NAME", Usage: "name of the generated map variable", Required: true, Repeat: true},
+		cli.Param{Name: "DIR_HOLDING_ASSETS", Type: "dir", Usage: "directory holding the asset files", Required: true, Repeat: true},
+	)
 
 	// Add Help Docs
 	app.AddHelp("license", []byte(fmt.Sprintf(pkgassets.LicenseText, appName, pkgassets.Version)))
@@ -106,111 +101,60 @@ func main() {
 	app.AddHelp("description", []byte(description))
 	app.AddHelp("examples", []byte(examples))
 
-	// Standard Options
-	app.BoolVar(&showHelp, "h,help", false, "display help")
-	app.BoolVar(&showLicense, "l,license", false, "display license")
-	app.BoolVar(&showVersion, "v,version", false, "display version")
+	// Standard Options, assets are read from directories so there is no -input
+	std := app.AddStandardOptions("input")
+	app.OptionGroup("Standard Options")
 	app.BoolVar(&showVerbose, "V,verbose", false, "verbose output")
-	app.BoolVar(&showExamples, "examples", false, "display example(s)")
-	app.StringVar(&outputFName, "o,output", "", "output filename")
-	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate markdown documentation")
-	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
-	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
 
 	// App Options
+	app.OptionGroup("Application Options")
 	app.StringVar(&packageName, "p", "", "package name, if missing defauls to lowercase of variable name")
 	app.StringVar(&packageName, "package", "", "package name, if missing defauls to lowercase of variable name")
-	app.StringVar(&commentFName, "c", "", "comment file to be included")
-	app.StringVar(&commentFName, "comment", "", "comment file to be included")
+	app.FileVar(&commentFName, "c", "", "comment file to be included")
+	app.FileVar(&commentFName, "comment", "", "comment file to be included")
 	app.StringVar(&stripPrefix, "strip-prefix", "", "strip the prefix from the map key")
 	app.StringVar(&stripSuffix, "strip-suffix", "", "strip the suffix from the map key")
 	app.StringVar(&requiredExt, "ext", "", "Only include files with matching extension")
 	app.StringVar(&excludeFNames, "X,exclude", "", "A colon separted list of filenames to exclude, (e.g. 'nav.md:topics.md')")
+	// NOTE: -p and -c are retired in favor of -package and -comment
+	app.Deprecate("p", "package")
+	app.Deprecate("c", "comment")
 
 	app.Parse()
-	args := app.Args()
-
-	// Setup IO
-	var err error
 
+	// Setup IO and handle the standard options
 	app.Eout = os.Stderr
-
-	/*
-		app.In, err = cli.Open(inputFName, os.Stdin)
-		cli.ExitOnError(app.Eout, err, quiet)
-		defer cli.CloseFile(inputFName, app.In)
-	*/
-
-	app.Out, err = cli.Create(outputFName, os.Stdout)
-	cli.ExitOnError(app.Eout, err, quiet)
-	defer cli.CloseFile(outputFName, app.Out)
-
-	// Process flags and update the environment as needed.
-	if generateMarkdown {
-		app.GenerateMarkdown(app.Out)
+	exit, err := app.HandleStandardOptions()
+	cli.ExitOnError(app.Eout, err, std.Quiet)
+	defer app.CloseStandardOptions()
+	if exit {
 		os.Exit(0)
 	}
-	if generateManPage {
-		app.GenerateManPage(app.Out)
-		os.Exit(0)
-	}
-	if showHelp || showExamples {
-		if len(args) > 0 {
-			fmt.Fprintln(app.Out, app.Help(args...))
-		} else {
-			app.Usage(app.Out)
-		}
-		os.Exit(0)
-	}
-	if showLicense {
-		fmt.Fprintln(app.Out, app.License())
-		os.Exit(0)
-	}
-	if showVersion {
-		fmt.Fprintln(app.Out, app.Version())
-		os.Exit(0)
-	}
-
-	if len(args) < 2 {
-		app.Usage(app.Eout)
-		fmt.Fprintf(app.Eout, "\n")
-		if len(args) < 1 {
-			fmt.Fprintf(app.Eout, "Missing map variable name\n")
-		}
-		if len(args) < 2 {
-			fmt.Fprintf(app.Eout, "Missing asset directory name\n")
-		}
-		os.Exit(1)
-	}
-	if (len(args) % 2) != 0 {
-		fmt.Fprintf(app.Eout, "Missing variable/assets directory names must be paired\n")
-		fmt.Fprintf(app.Eout, "Paramters provided, %q\n", strings.Join(args, ", "))
-		os.Exit(1)
-	}
 
 	excluded := strings.Split(excludeFNames, ":")
 
+	// NOTE: Parse has checked the VARIABLE_NAME/DIR_HOLDING_ASSETS pairs
+	mapVNames := app.ParamValues("VARIABLE_NAME")
+	assetDirs := app.ParamValues("DIR_HOLDING_ASSETS")
+
 	// For each pair of mapVName/assetDir add a map to outFName
-	for i := 0; (i + 1) < len(args); i += 2 {
-		mapVName, assetDir := args[i], args[i+1]
+	for i := 0; i < len(mapVNames); i++ {
+		mapVName, assetDir := mapVNames[i].(string), assetDirs[i].(string)
 		if mapVName == "" {
-			cli.ExitOnError(app.Eout, fmt.Errorf("Expected mapVName to be non-empty stirng for parameter %d", i), quiet)
+			cli.ExitOnError(app.Eout, fmt.Errorf("Expected mapVName to be non-empty stirng for parameter %d", i*2), std.Quiet)
 		}
 		if assetDir == "" {
-			cli.ExitOnError(app.Eout, fmt.Errorf("Expected assetDir to be non-empty string for parameter %d", i+1), quiet)
+			cli.ExitOnError(app.Eout, fmt.Errorf("Expected assetDir to be non-empty string for parameter %d", i*2+1), std.Quiet)
 		}
 
 		if packageName == "" {
 			packageName = strings.ToLower(mapVName)
 		}
-		if outputFName == "" {
-			outputFName = strings.ToLower(packageName) + ".go"
-		}
 		commentSrc := []byte{}
 		if commentFName != "" {
 			commentSrc, err = ioutil.ReadFile(commentFName)
 			if err != nil {
-				cli.ExitOnError(app.Eout, fmt.Errorf("Can't read %s, %s\n", commentFName, err), quiet)
+				cli.ExitOnError(app.Eout, fmt.Errorf("Can't read %s, %s\n", commentFName, err), std.Quiet)
 			}
 		}
 
@@ -248,18 +192,18 @@ func main() {
 			if requiredExt == "" || requiredExt == fExt {
 				bArray, err := ioutil.ReadFile(walkingPath)
 				if err != nil {
-					cli.OnError(app.Eout, fmt.Errorf("Can't read %q, %s", walkingPath, err), quiet)
+					cli.OnError(app.Eout, fmt.Errorf("Can't read %q, %s", walkingPath, err), std.Quiet)
 					return nil
 				}
 				if bSrc, err := pkgassets.ByteArrayToDecl(bArray); err == nil {
 					fmt.Fprintf(app.Out, "\n    %q: %s,\n", fPath, bSrc)
 				} else {
-					cli.OnError(app.Eout, fmt.Errorf("Can't convert to byte array notation %s, %s\n", walkingPath, err), quiet)
+					cli.OnError(app.Eout, fmt.Errorf("Can't convert to byte array notation %s, %s\n", walkingPath, err), std.Quiet)
 				}
 			}
 			return nil
 		}); err != nil {
-			cli.OnError(app.Eout, fmt.Errorf("Can't walk path %q, %s\n", assetDir, err), quiet)
+			cli.OnError(app.Eout, fmt.Errorf("Can't walk path %q, %s\n", assetDir, err), std.Quiet)
 		}
 		fmt.Fprintf(app.Out, `
 	}
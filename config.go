@@ -0,0 +1,264 @@
+// config.go - loads settings for a Cli from a JSON or simple INI
+// configuration file. Precedence is default < config < env < flags.
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configFile describes where a Cli looks for its configuration file
+type configFile struct {
+	// option names the file on the command line, e.g. -config
+	option *Option
+	// envName is the environment variable naming the file
+	envName string
+	// fileName is the file looked for in the XDG config directory
+	fileName string
+	// name holds the value of the config option
+	name string
+	// loaded is the path of the file read, if any
+	loaded string
+}
+
+// ConfigFile enables loading settings from a configuration file. The
+// file is named by the option names (e.g. "config"), else the environment
+// variable envName (e.g. DATASET_CONFIG), else fileName (e.g. "dataset.json")
+// in $XDG_CONFIG_HOME/APP_NAME (default ~/.config/APP_NAME).
+//
+// Files ending in ".json" hold a JSON object, otherwise the file is read
+// as simple INI, e.g. "key = value" lines with "#" or ";" comments,
+// section headers are ignored. Keys are option names or environment
+// variable names. Values from the file are overridden by the environment
+// and the command line.
+func (c *Cli) ConfigFile(names string, envName string, fileName string) {
+	cfg := &configFile{envName: envName, fileName: fileName}
+	if names != "" {
+		c.StringVar(&cfg.name, names, "", "read settings from `FILE`")
+		cfg.option = c.options[len(c.options)-1]
+	}
+	if envName != "" {
+		c.env[envName] = &EnvAttribute{
+			Name:  envName,
+			Type:  "string",
+			Usage: "configuration file to read settings from",
+		}
+	}
+	c.config = cfg
+}
+
+// ConfigPath returns the path of the configuration file loaded by
+// Parse() or ParseArgs(), an empty string if none was read.
+func (c *Cli) ConfigPath() string {
+	if c.config == nil {
+		return ""
+	}
+	return c.config.loaded
+}
+
+// defaultConfigPath returns fileName in the XDG config directory
+func (c *Cli) defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, c.appName, c.config.fileName)
+}
+
+// configPath returns the configuration file named in args, the
+// environment or the default location. Args are scanned with the same
+// rules as the options are parsed, e.g. stopping at the first positional
+// argument. The boolean is true when the file was named explicitly and
+// so must exist.
+func (c *Cli) configPath(args []string) (string, bool) {
+	if c.config.option != nil {
+		fName := ""
+		p := c.newParser()
+		p.scan = func(o *Option, value string) {
+			if o == c.config.option {
+				fName = value
+			}
+		}
+		// NOTE: parse errors are reported when the options are parsed
+		p.parse(args)
+		if fName != "" {
+			return fName, true
+		}
+	}
+	if c.config.envName != "" {
+		if s := strings.TrimSpace(os.Getenv(c.config.envName)); s != "" {
+			return s, true
+		}
+	}
+	if c.config.fileName != "" {
+		return c.defaultConfigPath(), false
+	}
+	return "", false
+}
+
+// loadConfig reads the configuration file, if any, setting the options
+// and environment attributes it names.
+func (c *Cli) loadConfig(args []string) error {
+	if c.config == nil {
+		return nil
+	}
+	fName, explicit := c.configPath(args)
+	if fName == "" {
+		return nil
+	}
+	src, err := ioutil.ReadFile(fName)
+	if err != nil {
+		if explicit == false && os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var settings map[string][]string
+	if strings.ToLower(path.Ext(fName)) == ".json" {
+		settings, err = parseJSONConfig(src)
+	} else {
+		settings, err = parseINIConfig(src)
+	}
+	if err != nil {
+		return fmt.Errorf("%s, %s", fName, err)
+	}
+	keys := []string{}
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := c.setConfig(k, settings[k]); err != nil {
+			return fmt.Errorf("%s, %s", fName, err)
+		}
+	}
+	c.config.loaded = fName
+	return nil
+}
+
+// setConfig sets an option or environment attribute from the config file
+func (c *Cli) setConfig(key string, values []string) error {
+	if o := findOption(c.options, key); o != nil {
+		for _, val := range values {
+			if err := o.setFrom(val, SourceConfig); err != nil {
+				return fmt.Errorf("invalid value %q for %q, %s", val, key, err)
+			}
+		}
+		return nil
+	}
+	if e, ok := c.env[key]; ok == true {
+		for _, val := range values {
+			if err := e.set(val, SourceConfig); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%q is an unsupported setting", key)
+}
+
+// parseJSONConfig reads a JSON object, arrays set an option once per
+// element. Values must be strings, numbers or booleans.
+func parseJSONConfig(src []byte) (map[string][]string, error) {
+	obj := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	settings := map[string][]string{}
+	for k, v := range obj {
+		if list, ok := v.([]interface{}); ok == true {
+			for _, item := range list {
+				s, err := jsonScalar(k, item)
+				if err != nil {
+					return nil, err
+				}
+				settings[k] = append(settings[k], s)
+			}
+			continue
+		}
+		s, err := jsonScalar(k, v)
+		if err != nil {
+			return nil, err
+		}
+		settings[k] = []string{s}
+	}
+	return settings, nil
+}
+
+// jsonScalar returns a string, number or boolean JSON value as a string,
+// null, objects and nested arrays are unsupported.
+func jsonScalar(key string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return fmt.Sprintf("%t", val), nil
+	}
+	return "", fmt.Errorf("%s: unsupported value", key)
+}
+
+// parseINIConfig reads "key = value" lines, blank lines, comments
+// starting with "#" or ";" and section headers are skipped. Repeated
+// keys set an option more than once.
+func parseINIConfig(src []byte) (map[string][]string, error) {
+	settings := map[string][]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		pos := strings.Index(line, "=")
+		if pos < 1 {
+			return nil, fmt.Errorf("line %d, expected key = value", lineNo)
+		}
+		key, value := strings.TrimSpace(line[0:pos]), strings.TrimSpace(line[pos+1:])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		settings[key] = append(settings[key], value)
+	}
+	return settings, scanner.Err()
+}
+
+// configDoc describes the configuration file for the FILES section
+func (c *Cli) configDoc() (string, string) {
+	location := ""
+	if c.config.fileName != "" {
+		location = path.Join("$XDG_CONFIG_HOME", c.appName, c.config.fileName)
+	}
+	parts := []string{"Settings are read from a JSON object or INI (key = value) file, keys are option or environment variable names."}
+	from := []string{}
+	if c.config.option != nil {
+		from = append(from, opsLabel([]string{c.config.option.longName()}, c.GNUStyle))
+	}
+	if c.config.envName != "" {
+		from = append(from, c.config.envName)
+	}
+	if len(from) > 0 {
+		parts = append(parts, fmt.Sprintf("The file can be set with %s.", strings.Join(from, " or ")))
+	}
+	parts = append(parts, "Environment variables and options override the file. Settings from the file count as given when checking required options and option constraints.")
+	if location == "" {
+		location = "configuration file"
+	}
+	return location, strings.Join(parts, " ")
+}
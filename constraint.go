@@ -0,0 +1,176 @@
+// constraint.go - declarative rules between the options of a Cli or
+// Verb, e.g. options that are mutually exclusive or require another.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const (
+	// exclusiveConstraint allows at most one of the options to be set
+	exclusiveConstraint = iota
+	// requiresConstraint requires names be set when name is set
+	requiresConstraint
+	// atLeastOneConstraint requires one or more of the options be set
+	atLeastOneConstraint
+	// impliesConstraint sets names when name is set
+	impliesConstraint
+)
+
+// constraint describes a rule checked after the options are parsed
+type constraint struct {
+	kind int
+	// name is the option a requires or implies rule applies to
+	name string
+	// names are the options the rule refers to, for implies rules
+	// they may include a value, e.g. "format=json".
+	names []string
+}
+
+// newConstraint checks the option names are defined and returns the constraint
+func newConstraint(options []*Option, kind int, name string, names []string) (*constraint, error) {
+	if kind == requiresConstraint || kind == impliesConstraint {
+		if findOption(options, name) == nil {
+			return nil, fmt.Errorf("%q is an unsupported option", name)
+		}
+	} else if len(names) < 2 {
+		return nil, fmt.Errorf("expected two or more options, got %q", strings.Join(names, ", "))
+	}
+	for _, op := range names {
+		op, _ = splitImplied(op)
+		if findOption(options, op) == nil {
+			return nil, fmt.Errorf("%q is an unsupported option", op)
+		}
+	}
+	return &constraint{kind: kind, name: name, names: names}, nil
+}
+
+// splitImplied splits an implied option into name and value,
+// e.g. "format=json" returns "format", "json". If there is no value
+// "true" is returned.
+func splitImplied(s string) (string, string) {
+	if pos := strings.Index(s, "="); pos > 0 {
+		return s[0:pos], s[pos+1:]
+	}
+	return s, "true"
+}
+
+// labels returns the option names as documented, e.g. "-output"
+func labels(options []*Option, names []string, gnu bool) []string {
+	parts := []string{}
+	for _, name := range names {
+		name, value := splitImplied(name)
+		if o := findOption(options, name); o != nil {
+			name = o.longName()
+		}
+		label := opsLabel([]string{name}, gnu)
+		if value != "true" {
+			label += "=" + value
+		}
+		parts = append(parts, label)
+	}
+	return parts
+}
+
+// joinAnd joins a list of labels, e.g. "-a, -b and -c"
+func joinAnd(parts []string) string {
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	last := len(parts) - 1
+	return strings.Join(parts[0:last], ", ") + " and " + parts[last]
+}
+
+// describe returns the rule as a sentence for the documentation
+func (r *constraint) describe(options []*Option, gnu bool) string {
+	names := labels(options, r.names, gnu)
+	switch r.kind {
+	case exclusiveConstraint:
+		return fmt.Sprintf("%s are mutually exclusive", joinAnd(names))
+	case requiresConstraint:
+		return fmt.Sprintf("%s requires %s", labels(options, []string{r.name}, gnu)[0], joinAnd(names))
+	case atLeastOneConstraint:
+		return fmt.Sprintf("at least one of %s is required", strings.Join(names, ", "))
+	case impliesConstraint:
+		return fmt.Sprintf("%s implies %s", labels(options, []string{r.name}, gnu)[0], joinAnd(names))
+	}
+	return ""
+}
+
+// isOptionSet returns true if the named option was set. Options set from
+// a configuration file or environment variable count as set so the
+// constraints apply to them as well as to the command line.
+func isOptionSet(options []*Option, set map[string]bool, name string) bool {
+	if o := findOption(options, name); o != nil {
+		return o.isSet(set)
+	}
+	return set[name]
+}
+
+// applyImplied sets the options implied by the options given unless they
+// were also given. Implied options take the source of the option
+// implying them, e.g. the config file.
+func applyImplied(fs *flag.FlagSet, options []*Option, constraints []*constraint) error {
+	set := visited(fs)
+	for _, r := range constraints {
+		if r.kind != impliesConstraint || isOptionSet(options, set, r.name) == false {
+			continue
+		}
+		src := SourceCommandLine
+		if o := findOption(options, r.name); o != nil && o.Source != SourceDefault {
+			src = o.Source
+		}
+		for _, s := range r.names {
+			name, value := splitImplied(s)
+			if isOptionSet(options, set, name) {
+				continue
+			}
+			if err := fs.Set(strings.TrimLeft(name, "-"), value); err != nil {
+				return &ParseError{Kind: InvalidValue, Option: labels(options, []string{name}, false)[0], Value: value, Err: err}
+			}
+			if o := findOption(options, name); o != nil {
+				o.Source = src
+			}
+		}
+	}
+	return nil
+}
+
+// checkConstraints returns a *ParseError for the first rule not met.
+// At least one of rules are skipped when help, version, etc. was requested.
+func checkConstraints(options []*Option, constraints []*constraint, set map[string]bool, gnu bool) error {
+	for _, r := range constraints {
+		cnt := 0
+		for _, name := range r.names {
+			if isOptionSet(options, set, name) {
+				cnt++
+			}
+		}
+		failed := false
+		option := ""
+		switch r.kind {
+		case exclusiveConstraint:
+			failed = cnt > 1
+		case requiresConstraint:
+			failed = isOptionSet(options, set, r.name) && cnt < len(r.names)
+			option = labels(options, []string{r.name}, gnu)[0]
+		case atLeastOneConstraint:
+			failed = cnt == 0 && isInfoRequest(options, set) == false
+		}
+		if failed {
+			return &ParseError{Kind: ConstraintViolation, Option: option, Err: fmt.Errorf("%s", r.describe(options, gnu))}
+		}
+	}
+	return nil
+}
+
+// constraintNotes returns the rules as sentences for the documentation
+func constraintNotes(options []*Option, constraints []*constraint, gnu bool) []string {
+	notes := []string{}
+	for _, r := range constraints {
+		notes = append(notes, r.describe(options, gnu))
+	}
+	return notes
+}
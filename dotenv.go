@@ -0,0 +1,128 @@
+// dotenv.go - reads dotenv files (e.g. ".env") so ParseEnv can use
+// settings developers keep alongside a project.
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// isEnvName returns true if s is a valid environment variable name
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// expandEnv replaces $NAME and ${NAME} in s, values from the real
+// environment win over those already read from the dotenv file.
+func expandEnv(s string, values map[string]string) string {
+	return os.Expand(s, func(name string) string {
+		if val, ok := os.LookupEnv(name); ok == true {
+			return val
+		}
+		return values[name]
+	})
+}
+
+// unquoteDotEnv returns the value of a double quoted string starting
+// at s[0] with \n, \t, \" and \\ escapes, and the text after it.
+func unquoteDotEnv(s string) (string, string, error) {
+	var buf strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return buf.String(), s[i+1:], nil
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					buf.WriteByte('\n')
+				case 't':
+					buf.WriteByte('\t')
+				default:
+					buf.WriteByte(s[i])
+				}
+				continue
+			}
+		}
+		buf.WriteByte(s[i])
+	}
+	return "", "", fmt.Errorf("missing closing quote")
+}
+
+// parseDotEnv reads NAME=VALUE lines. Blank lines and "#" comments are
+// skipped, an "export " prefix is allowed. Single quoted values are
+// literal, double quoted and unquoted values expand $NAME and ${NAME}.
+func parseDotEnv(src []byte, values map[string]string) error {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		pos := strings.Index(line, "=")
+		if pos < 1 || isEnvName(strings.TrimSpace(line[0:pos])) == false {
+			return fmt.Errorf("line %d, expected NAME=VALUE", lineNo)
+		}
+		name, value := strings.TrimSpace(line[0:pos]), strings.TrimSpace(line[pos+1:])
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return fmt.Errorf("line %d, missing closing quote", lineNo)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, "\""):
+			s, _, err := unquoteDotEnv(value)
+			if err != nil {
+				return fmt.Errorf("line %d, %s", lineNo, err)
+			}
+			value = expandEnv(s, values)
+		default:
+			// NOTE: an unquoted value ends at a comment
+			if pos := strings.Index(value, " #"); pos >= 0 {
+				value = strings.TrimSpace(value[0:pos])
+			}
+			value = expandEnv(value, values)
+		}
+		values[name] = value
+	}
+	return scanner.Err()
+}
+
+// readDotEnv reads the dotenv files in order, later files override
+// earlier ones. Files that don't exist are skipped.
+func readDotEnv(fNames []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, fName := range fNames {
+		src, err := ioutil.ReadFile(fName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err := parseDotEnv(src, values); err != nil {
+			return nil, fmt.Errorf("%s, %s", fName, err)
+		}
+	}
+	return values, nil
+}
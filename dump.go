@@ -0,0 +1,163 @@
+// dump.go - writes the effective configuration of a run, i.e. each
+// option and environment attribute with its value, default and source,
+// as text or JSON for bug reports and reproducing a run.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// redacted replaces the value of a secret option or environment attribute
+const redacted = "********"
+
+// configEntry describes the effective value of an option or environment
+// attribute
+type configEntry struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	Source  string `json:"source"`
+	Env     string `json:"env,omitempty"`
+
+	// label is the name as displayed, e.g. "-output" or "DATASET"
+	label string
+}
+
+// redact returns s or, if it is not empty, the redacted marker
+func redact(s string) string {
+	if s == "" {
+		return s
+	}
+	return redacted
+}
+
+// Secret marks the named options (e.g. "password") or environment
+// attributes (e.g. "API_KEY") as secrets. Their values are redacted by
+// ShowConfig() and DumpConfig() and their defaults left out of the help.
+func (c *Cli) Secret(names ...string) error {
+	for _, name := range names {
+		if o := findOption(c.options, name); o != nil {
+			o.Secret = true
+			continue
+		}
+		if e, ok := c.env[name]; ok == true {
+			e.Secret = true
+			continue
+		}
+		return fmt.Errorf("%q is an unsupported option or environment variable", name)
+	}
+	return nil
+}
+
+// isSecret returns true if the option or the environment attribute
+// bound to it is a secret
+func (c *Cli) isSecret(o *Option) bool {
+	if o.Secret {
+		return true
+	}
+	e, ok := c.env[o.EnvVar]
+	return ok == true && e.Secret
+}
+
+// configEntries returns the options in declaration order followed by
+// the environment attributes sorted by name with secrets redacted. An
+// attribute bound to an option is reported with the option.
+func (c *Cli) configEntries() []*configEntry {
+	entries := []*configEntry{}
+	for _, o := range c.options {
+		if o.Type == "func" {
+			continue
+		}
+		entry := &configEntry{
+			Name:    o.longName(),
+			Kind:    "option",
+			Type:    o.Type,
+			Value:   o.String(),
+			Default: o.Default,
+			Source:  o.Source.String(),
+			Env:     o.EnvVar,
+			label:   opsLabel([]string{o.longName()}, c.GNUStyle),
+		}
+		if o.EnvVar != "" {
+			entry.label += ", " + o.EnvVar
+		}
+		if c.isSecret(o) {
+			entry.Value, entry.Default = redact(entry.Value), redact(entry.Default)
+		}
+		entries = append(entries, entry)
+	}
+	keys := []string{}
+	for k, e := range c.env {
+		if e.option == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e := c.env[k]
+		entry := &configEntry{
+			Name:    k,
+			Kind:    "environment",
+			Type:    e.Type,
+			Value:   e.String(),
+			Default: e.defaultString(),
+			Source:  e.Source.String(),
+			label:   k,
+		}
+		if e.Secret {
+			entry.Value, entry.Default = redact(entry.Value), redact(entry.Default)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// configPadding returns the width of the widest label
+func configPadding(entries []*configEntry) int {
+	padding := 0
+	for _, entry := range entries {
+		if len(entry.label) > padding {
+			padding = len(entry.label) + 1
+		}
+	}
+	return padding
+}
+
+// ShowConfig writes the effective value of each option and environment
+// attribute along with where it came from and its default, e.g. for the
+// -show-config option. It is DumpConfig() in the text format.
+func (c *Cli) ShowConfig(w io.Writer) {
+	c.DumpConfig(w, "text")
+}
+
+// DumpConfig writes each option and environment attribute with its
+// current value, default and source. The format is "text" or "json",
+// secrets (see Secret()) are redacted.
+func (c *Cli) DumpConfig(w io.Writer, format string) error {
+	entries := c.configEntries()
+	switch format {
+	case "", "text":
+		padding := configPadding(entries)
+		for _, entry := range entries {
+			if entry.Source == SourceDefault.String() {
+				fmt.Fprintf(w, "%s  %q (%s)\n", padRight(entry.label, " ", padding), entry.Value, entry.Source)
+			} else {
+				fmt.Fprintf(w, "%s  %q (%s, default %q)\n", padRight(entry.label, " ", padding), entry.Value, entry.Source, entry.Default)
+			}
+		}
+	case "json":
+		src, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", src)
+	default:
+		return fmt.Errorf("%q is an unsupported format, expected text or json", format)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@
 package cli
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -51,8 +52,38 @@ type EnvAttribute struct {
 	DurationValue time.Duration
 	// StringValue holds the default string
 	StringValue string
+	// TimeValue holds the default time.Time
+	TimeValue time.Time
 	// Usage describes the environment variable role and expected setting
 	Usage string
+	// Required is true if the environment variable must be set
+	Required bool
+	// Source is where the attribute's current value came from
+	Source Source
+	// Secret is true if the value is redacted when the configuration is
+	// displayed, e.g. a password or API key
+	Secret bool
+
+	// option is set when the attribute is bound to an option, e.g. by
+	// StringVarEnv(), the option holds the value.
+	option *Option
+	// defaultValue holds the declared default once the value is set
+	defaultValue string
+}
+
+// doc returns the usage along with any notes, e.g. if it is required
+func (e *EnvAttribute) doc(gnu bool) string {
+	parts := []string{e.Usage}
+	if e.Type == "time.Time" {
+		parts = append(parts, fmt.Sprintf("(%s)", TimeFormats))
+	}
+	if e.option != nil {
+		parts = append(parts, fmt.Sprintf("(option %s)", opsLabel([]string{e.option.longName()}, gnu)))
+	}
+	if e.Required {
+		parts = append(parts, "(required)")
+	}
+	return strings.Join(parts, " ")
 }
 
 // EnvBool adds an environment variable which is evaluate before evaluating options
@@ -323,6 +354,37 @@ func (c *Cli) EnvDurationVar(p *time.Duration, name string, value time.Duration,
 	return nil
 }
 
+// EnvTime adds a time.Time environment variable which is evaluated before
+// evaluating options, see ParseTime for the formats accepted. Returns a
+// pointer to the value.
+func (c *Cli) EnvTime(name string, value time.Time, usage string) *time.Time {
+	c.env[name] = &EnvAttribute{
+		Name:      name,
+		Type:      fmt.Sprintf("%T", value),
+		TimeValue: value,
+		Usage:     usage,
+	}
+	return &c.env[name].TimeValue
+}
+
+// EnvTimeVar adds a time.Time environment variable which is evaluated
+// before evaluating options, see ParseTime for the formats accepted.
+// It is the environment counterpart to EnvTime()
+func (c *Cli) EnvTimeVar(p *time.Time, name string, value time.Time, usage string) error {
+	c.env[name] = &EnvAttribute{
+		Name:      name,
+		Type:      fmt.Sprintf("%T", value),
+		TimeValue: value,
+		Usage:     usage,
+	}
+	*p = c.env[name].TimeValue
+	_, ok := c.env[name]
+	if ok == false {
+		return fmt.Errorf("%q could not be added to environment attributes", name)
+	}
+	return nil
+}
+
 // EnvAttribute returns the struct corresponding to the matchine name
 func (c *Cli) EnvAttribute(name string) (*EnvAttribute, error) {
 	e, ok := c.env[name]
@@ -332,6 +394,19 @@ func (c *Cli) EnvAttribute(name string) (*EnvAttribute, error) {
 	return e, nil
 }
 
+// RequiredEnv marks the named environment attributes as required. When
+// parsed with ParseArgs() any that are not set are reported as missing.
+func (c *Cli) RequiredEnv(names ...string) error {
+	for _, name := range names {
+		e, err := c.EnvAttribute(name)
+		if err != nil {
+			return err
+		}
+		e.Required = true
+	}
+	return nil
+}
+
 // Env returns an EnvAttribute documentation string for matching name
 func (c *Cli) Env(name string) string {
 	e, ok := c.env[name]
@@ -343,10 +418,17 @@ func (c *Cli) Env(name string) string {
 
 // Getenv returns a given environment attribute value as a string
 func (c *Cli) Getenv(name string) string {
-	var s string
 	e, err := c.EnvAttribute(name)
 	if err != nil {
-		return s
+		return ""
+	}
+	return e.String()
+}
+
+// String returns the attribute's current value as a string
+func (e *EnvAttribute) String() string {
+	if e.option != nil {
+		return e.option.String()
 	}
 	switch e.Type {
 	case "bool":
@@ -363,45 +445,151 @@ func (c *Cli) Getenv(name string) string {
 		return fmt.Sprintf("%f", e.Float64Value)
 	case "time.Duration":
 		return fmt.Sprintf("%s", e.DurationValue)
+	case "time.Time":
+		return (*timeValue)(&e.TimeValue).String()
 	}
 	return e.StringValue
 }
 
-// ParseEnv loops through the os environment using os.Getenv() and updates
-// c.env EnvAttribute. Returns an error if there is a problem with environment.
-func (c *Cli) ParseEnv() error {
+// defaultString returns the attribute's declared default as a string
+func (e *EnvAttribute) defaultString() string {
+	if e.option != nil {
+		return e.option.Default
+	}
+	if e.Source == SourceDefault {
+		return e.String()
+	}
+	return e.defaultValue
+}
+
+// set updates the attribute from the string s converting it to e.Type
+// and records the source.
+func (e *EnvAttribute) set(s string, src Source) error {
 	var (
 		err error
 		u64 uint64
 	)
+	if e.option != nil {
+		if err = e.option.setFrom(s, src); err != nil {
+			return fmt.Errorf("%q should be type %q, %s", e.Name, e.Type, err)
+		}
+		e.Source = src
+		return nil
+	}
+	if e.Source == SourceDefault {
+		e.defaultValue = e.String()
+	}
+	switch e.Type {
+	case "bool":
+		e.BoolValue, err = strconv.ParseBool(s)
+	case "int":
+		e.IntValue, err = strconv.Atoi(s)
+	case "int64":
+		e.Int64Value, err = strconv.ParseInt(s, 10, 64)
+	case "uint":
+		u64, err = strconv.ParseUint(s, 10, 32)
+		e.UintValue = uint(u64)
+	case "uint64":
+		e.Uint64Value, err = strconv.ParseUint(s, 10, 64)
+	case "float64":
+		e.Float64Value, err = strconv.ParseFloat(s, 64)
+	case "time.Duration":
+		e.DurationValue, err = time.ParseDuration(s)
+	case "time.Time":
+		e.TimeValue, err = ParseTime(s)
+	default:
+		e.StringValue = s
+	}
+	if err != nil {
+		return fmt.Errorf("%q should be type %q, %s", e.Name, e.Type, err)
+	}
+	e.Source = src
+	return nil
+}
+
+// ParseEnv loops through the os environment using os.Getenv() and updates
+// c.env EnvAttribute. Returns an error if there is a problem with environment.
+// Options bound to an environment variable (e.g. by StringVarEnv()) are
+// set from the environment, the command line can then override them.
+// Variables not set in the environment are looked up in c.DotEnvFiles.
+func (c *Cli) ParseEnv() error {
+	dotEnv, err := readDotEnv(c.DotEnvFiles)
+	if err != nil {
+		return err
+	}
 	for k, e := range c.env {
-		s := strings.TrimSpace(os.Getenv(k))
+		s, src := strings.TrimSpace(os.Getenv(k)), SourceEnvironment
+		if s == "" {
+			// NOTE: the real environment wins over dotenv files
+			s, src = strings.TrimSpace(dotEnv[k]), SourceDotEnv
+		}
 		// NOTE: we only parse the environment if it is not an emprt string
 		if s != "" {
-			switch e.Type {
-			case "bool":
-				e.BoolValue, err = strconv.ParseBool(s)
-			case "int":
-				e.IntValue, err = strconv.Atoi(s)
-			case "int64":
-				e.Int64Value, err = strconv.ParseInt(s, 10, 64)
-			case "uint":
-				u64, err = strconv.ParseUint(s, 10, 32)
-				e.UintValue = uint(u64)
-			case "uint64":
-				e.Uint64Value, err = strconv.ParseUint(s, 10, 64)
-			case "float64":
-				e.Float64Value, err = strconv.ParseFloat(s, 64)
-			case "time.Duration":
-				e.DurationValue, err = time.ParseDuration(s)
-			default:
-				e.StringValue = s
-			}
-			if err != nil {
-				return fmt.Errorf("%q should be type %q, %s", e.Name, e.Type, err)
+			if err := e.set(s, src); err != nil {
+				return err
 			}
 		}
-		c.env[k] = e
 	}
-	return err
+	return nil
+}
+
+// VarEnv defines an option with the flag.Value and names (e.g. "d,dataset")
+// bound to the environment variable envName (e.g. DATASET). Parse()
+// sets the value from the environment then the command line so the
+// option overrides the variable. Both are documented.
+func (c *Cli) VarEnv(value flag.Value, names string, envName string, usage string) {
+	c.Var(value, names, usage)
+	o := c.options[len(c.options)-1]
+	o.EnvVar = envName
+	c.env[envName] = &EnvAttribute{
+		Name:   envName,
+		Type:   o.Type,
+		Usage:  usage,
+		option: o,
+	}
+}
+
+// BoolVarEnv defines a bool option bound to an environment variable
+func (c *Cli) BoolVarEnv(p *bool, names string, envName string, value bool, usage string) {
+	c.VarEnv(newBoolValue(value, p), names, envName, usage)
+}
+
+// IntVarEnv defines an int option bound to an environment variable
+func (c *Cli) IntVarEnv(p *int, names string, envName string, value int, usage string) {
+	c.VarEnv(newIntValue(value, p), names, envName, usage)
+}
+
+// Int64VarEnv defines an int64 option bound to an environment variable
+func (c *Cli) Int64VarEnv(p *int64, names string, envName string, value int64, usage string) {
+	c.VarEnv(newInt64Value(value, p), names, envName, usage)
+}
+
+// UintVarEnv defines a uint option bound to an environment variable
+func (c *Cli) UintVarEnv(p *uint, names string, envName string, value uint, usage string) {
+	c.VarEnv(newUintValue(value, p), names, envName, usage)
+}
+
+// Uint64VarEnv defines a uint64 option bound to an environment variable
+func (c *Cli) Uint64VarEnv(p *uint64, names string, envName string, value uint64, usage string) {
+	c.VarEnv(newUint64Value(value, p), names, envName, usage)
+}
+
+// StringVarEnv defines a string option bound to an environment variable
+func (c *Cli) StringVarEnv(p *string, names string, envName string, value string, usage string) {
+	c.VarEnv(newStringValue(value, p), names, envName, usage)
+}
+
+// Float64VarEnv defines a float64 option bound to an environment variable
+func (c *Cli) Float64VarEnv(p *float64, names string, envName string, value float64, usage string) {
+	c.VarEnv(newFloat64Value(value, p), names, envName, usage)
+}
+
+// TimeVarEnv defines a time.Time option bound to an environment variable
+func (c *Cli) TimeVarEnv(p *time.Time, names string, envName string, value time.Time, usage string) {
+	c.VarEnv(newTimeValue(value, p), names, envName, usage)
+}
+
+// DurationVarEnv defines a time.Duration option bound to an environment variable
+func (c *Cli) DurationVarEnv(p *time.Duration, names string, envName string, value time.Duration, usage string) {
+	c.VarEnv(newDurationValue(value, p), names, envName, usage)
 }
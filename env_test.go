@@ -21,8 +21,10 @@
 package cli
 
 import (
+	"bytes"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestAppEnv(t *testing.T) {
@@ -98,7 +100,7 @@ func TestAppEnv(t *testing.T) {
 	if expectedS != gotS {
 		t.Errorf("expected %q, got %q", expectedS, gotS)
 	}
-	gotS = app.Option("user")
+	gotS = app.Option("user2")
 	if expectedS != gotS {
 		t.Errorf("expected %q, got %q", expectedS, gotS)
 	}
@@ -106,3 +108,167 @@ func TestAppEnv(t *testing.T) {
 		t.Errorf("expected %s, got %s", expectedUserS, userName)
 	}
 }
+
+func TestVarEnv(t *testing.T) {
+	var (
+		dataset string
+		limit   int
+	)
+	os.Setenv("TEST_VARENV_DATASET", "env.ds")
+	os.Setenv("TEST_VARENV_LIMIT", "5")
+	defer os.Unsetenv("TEST_VARENV_DATASET")
+	defer os.Unsetenv("TEST_VARENV_LIMIT")
+
+	app := NewCli(Version)
+	app.StringVarEnv(&dataset, "d,dataset", "TEST_VARENV_DATASET", "", "dataset name")
+	app.IntVarEnv(&limit, "limit", "TEST_VARENV_LIMIT", 10, "maximum records")
+	if err := app.ParseArgs([]string{"-dataset", "flag.ds"}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if dataset != "flag.ds" {
+		t.Errorf("expected the option to override the environment, got %q", dataset)
+	}
+	if limit != 5 {
+		t.Errorf("expected limit from the environment, got %d", limit)
+	}
+	if s := app.Getenv("TEST_VARENV_LIMIT"); s != "5" {
+		t.Errorf("expected Getenv() to return 5, got %q", s)
+	}
+	if src, _ := app.ValueSource("limit"); src != SourceEnvironment {
+		t.Errorf("expected limit source environment, got %s", src)
+	}
+	if src, _ := app.ValueSource("dataset"); src != SourceCommandLine {
+		t.Errorf("expected dataset source command line, got %s", src)
+	}
+
+	os.Setenv("TEST_VARENV_LIMIT", "many")
+	if err := app.ParseArgs([]string{}); err == nil {
+		t.Errorf("expected ParseArgs() to fail for TEST_VARENV_LIMIT=many")
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	app.Usage(buf)
+	for _, expectedS := range []string{
+		"dataset name (option -dataset)",
+		"dataset name (env TEST_VARENV_DATASET)",
+	} {
+		if bytes.Contains(buf.Bytes(), []byte(expectedS)) == false {
+			t.Errorf("expected %q, got\n%s", expectedS, buf.Bytes())
+		}
+	}
+}
+
+func TestDotEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	fName := dir + "/.env"
+	os.WriteFile(fName, []byte(`# project settings
+export TEST_DOTENV_DATASET=dotenv.ds # trailing comment
+TEST_DOTENV_ROOT="/data/${TEST_DOTENV_DATASET}\tall"
+TEST_DOTENV_LITERAL='$HOME is not expanded'
+TEST_DOTENV_LIMIT=12
+`), 0664)
+	os.Setenv("TEST_DOTENV_LIMIT", "7")
+	defer os.Unsetenv("TEST_DOTENV_LIMIT")
+
+	app := NewCli(Version)
+	app.DotEnvFiles = []string{fName, dir + "/missing.env"}
+	dataset := app.EnvString("TEST_DOTENV_DATASET", "", "dataset name")
+	root := app.EnvString("TEST_DOTENV_ROOT", "", "root path")
+	literal := app.EnvString("TEST_DOTENV_LITERAL", "", "a literal value")
+	limit := app.EnvInt("TEST_DOTENV_LIMIT", 0, "maximum records")
+	app.RequiredEnv("TEST_DOTENV_DATASET")
+	if err := app.ParseArgs([]string{}); err != nil {
+		t.Errorf("expected ParseArgs() to succeed, %s", err)
+	}
+	if *dataset != "dotenv.ds" {
+		t.Errorf("expected dotenv.ds, got %q", *dataset)
+	}
+	if *root != "/data/dotenv.ds\tall" {
+		t.Errorf("expected expanded root, got %q", *root)
+	}
+	if *literal != "$HOME is not expanded" {
+		t.Errorf("expected literal value, got %q", *literal)
+	}
+	if *limit != 7 {
+		t.Errorf("expected the environment to win, got %d", *limit)
+	}
+	if src, _ := app.ValueSource("TEST_DOTENV_DATASET"); src != SourceDotEnv {
+		t.Errorf("expected dotenv file source, got %s", src)
+	}
+
+	os.WriteFile(fName, []byte("NOT A SETTING\n"), 0664)
+	if err := app.ParseEnv(); err == nil {
+		t.Errorf("expected ParseEnv() to fail for a malformed dotenv file")
+	}
+}
+
+func TestEnvTime(t *testing.T) {
+	os.Setenv("TEST_ENV_SINCE", "2021-01-02T03:04:05Z")
+	defer os.Unsetenv("TEST_ENV_SINCE")
+	app := NewCli(Version)
+	since := app.EnvTime("TEST_ENV_SINCE", time.Time{}, "harvest records since")
+	if err := app.ParseEnv(); err != nil {
+		t.Errorf("expected ParseEnv() to succeed, %s", err)
+	}
+	if since.Equal(time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC)) == false {
+		t.Errorf("expected 2021-01-02T03:04:05Z, got %s", since)
+	}
+	if s := app.Getenv("TEST_ENV_SINCE"); s != "2021-01-02T03:04:05Z" {
+		t.Errorf("expected Getenv() to return RFC3339, got %q", s)
+	}
+	os.Setenv("TEST_ENV_SINCE", "sometime")
+	if err := app.ParseEnv(); err == nil {
+		t.Errorf("expected ParseEnv() to fail for TEST_ENV_SINCE=sometime")
+	}
+}
+
+func TestEnvTimeVar(t *testing.T) {
+	var until time.Time
+	os.Setenv("TEST_ENV_UNTIL", "2021-02-03")
+	defer os.Unsetenv("TEST_ENV_UNTIL")
+	app := NewCli(Version)
+	start := time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if err := app.EnvTimeVar(&until, "TEST_ENV_UNTIL", start, "harvest records until"); err != nil {
+		t.Errorf("expected EnvTimeVar() to succeed, %s", err)
+	}
+	if until.Equal(start) == false {
+		t.Errorf("expected the default %s, got %s", start, until)
+	}
+	if err := app.ParseEnv(); err != nil {
+		t.Errorf("expected ParseEnv() to succeed, %s", err)
+	}
+	if s := app.Getenv("TEST_ENV_UNTIL"); s != "2021-02-03T00:00:00Z" {
+		t.Errorf("expected Getenv() to return 2021-02-03T00:00:00Z, got %q", s)
+	}
+}
+
+func TestVarEnvRequired(t *testing.T) {
+	var dataset string
+	os.Setenv("TEST_REQUIRED_DS", "x.ds")
+	defer os.Unsetenv("TEST_REQUIRED_DS")
+
+	// NOTE: the environment variable supplies the required option
+	app := NewCli(Version)
+	app.StringVarEnv(&dataset, "d,dataset", "TEST_REQUIRED_DS", "", "dataset name")
+	app.Required("dataset")
+	if err := app.ParseArgs([]string{}); err != nil || dataset != "x.ds" {
+		t.Errorf("expected the environment to supply -dataset, got %q, %v", dataset, err)
+	}
+
+	// NOTE: the option supplies the required environment variable
+	os.Unsetenv("TEST_REQUIRED_DS")
+	app = NewCli(Version)
+	app.StringVarEnv(&dataset, "d,dataset", "TEST_REQUIRED_DS", "", "dataset name")
+	app.RequiredEnv("TEST_REQUIRED_DS")
+	if err := app.ParseArgs([]string{"-dataset", "cli.ds"}); err != nil || dataset != "cli.ds" {
+		t.Errorf("expected -dataset to supply TEST_REQUIRED_DS, got %q, %v", dataset, err)
+	}
+
+	app = NewCli(Version)
+	app.StringVarEnv(&dataset, "d,dataset", "TEST_REQUIRED_DS", "", "dataset name")
+	app.Required("dataset")
+	err := app.ParseArgs([]string{})
+	if pe, ok := err.(*ParseError); ok == false || pe.Kind != MissingRequired {
+		t.Errorf("expected MissingRequired when neither is set, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // OnError writes an error message to out if err != nil
@@ -24,3 +25,101 @@ func ExitOnError(out *os.File, err error, quiet bool) {
 		os.Exit(1)
 	}
 }
+
+// ParseErrorKind identifies the type of problem found when parsing
+// command line options.
+type ParseErrorKind int
+
+const (
+	// UnknownOption is an option not defined for the cli or verb
+	UnknownOption ParseErrorKind = iota + 1
+	// InvalidValue is a value that could not be set for an option
+	InvalidValue
+	// MissingArgument is an option that requires a value but none was given
+	MissingArgument
+	// MissingRequired is one or more required options or environment
+	// variables that were not set
+	MissingRequired
+	// ConstraintViolation is an option rule that was not met, e.g. two
+	// mutually exclusive options were given
+	ConstraintViolation
+	// MissingParameter is one or more positional parameters not given
+	MissingParameter
+	// UnexpectedParameter is a positional parameter that wasn't expected
+	UnexpectedParameter
+)
+
+// String returns a short description of the error kind
+func (k ParseErrorKind) String() string {
+	switch k {
+	case UnknownOption:
+		return "unknown option"
+	case InvalidValue:
+		return "invalid value"
+	case MissingArgument:
+		return "missing argument"
+	case MissingRequired:
+		return "missing required"
+	case ConstraintViolation:
+		return "constraint violation"
+	case MissingParameter:
+		return "missing parameter"
+	case UnexpectedParameter:
+		return "unexpected parameter"
+	}
+	return "parse error"
+}
+
+// ParseError is returned by Cli.ParseArgs() and Verb.Parse() when the
+// command line options can not be parsed.
+type ParseError struct {
+	// Kind is the type of parse error
+	Kind ParseErrorKind
+	// Option is the option as given on the command line, e.g. "-o", or
+	// the name of a positional parameter
+	Option string
+	// Value holds the value being set when Kind is InvalidValue or
+	// the argument when Kind is UnexpectedParameter
+	Value string
+	// Err holds the underlying error, if any
+	Err error
+	// Suggestions holds the closest defined options when Kind is UnknownOption
+	Suggestions []string
+	// Missing holds the options, environment variables or parameters not set
+	// when Kind is MissingRequired
+	Missing []string
+}
+
+// Error returns the parse error as a string
+func (e *ParseError) Error() string {
+	switch e.Kind {
+	case UnknownOption:
+		return fmt.Sprintf("%q is an unsupported option%s", e.Option, didYouMean(e.Suggestions))
+	case InvalidValue:
+		if e.Err != nil {
+			return fmt.Sprintf("invalid value %q for %q, %s", e.Value, e.Option, e.Err)
+		}
+		return fmt.Sprintf("invalid value %q for %q", e.Value, e.Option)
+	case MissingArgument:
+		return fmt.Sprintf("%q requires an argument", e.Option)
+	case MissingRequired:
+		return fmt.Sprintf("missing required %s", strings.Join(e.Missing, ", "))
+	case MissingParameter:
+		return fmt.Sprintf("missing parameter %s", strings.Join(e.Missing, ", "))
+	case UnexpectedParameter:
+		return fmt.Sprintf("unexpected parameter %q", e.Value)
+	case ConstraintViolation:
+		if e.Err != nil {
+			return e.Err.Error()
+		}
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%q %s", e.Option, e.Err)
+	}
+	return fmt.Sprintf("%q %s", e.Option, e.Kind)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -119,6 +119,7 @@ func md2man(src []byte) []byte {
 // `nroff --man`. May need some human clean up depending on content and
 // internal formatting (e.g markdown style, spacing, etc.)
 func (c *Cli) GenerateManPage(w io.Writer) {
+	c.applyNegatable()
 	var parts []string
 
 	// .TH {appName} {section_no} {version} {date}
@@ -131,10 +132,11 @@ func (c *Cli) GenerateManPage(w io.Writer) {
 
 	// NOTE: if we've explicitly defined the parameters them here, otherwise
 	// extrapoliate from verbs and actions.
-	if len(c.params) > 0 {
-		parts = append(parts, c.params...)
+	params := c.paramNames()
+	if len(params) > 0 {
+		parts = append(parts, params...)
 	}
-	if len(c.verbs) > 0 && len(c.params) == 0 {
+	if len(c.verbs) > 0 && len(params) == 0 {
 		if c.VerbsRequired {
 			parts = append(parts, "VERB")
 		} else {
@@ -149,7 +151,7 @@ func (c *Cli) GenerateManPage(w io.Writer) {
 		}
 		// Check for verb params
 		for _, verb := range c.verbs {
-			if len(verb.params) > 0 {
+			if len(verb.paramNames()) > 0 {
 				parts = append(parts, "[VERB PARAMETERS...]")
 				break
 			}
@@ -162,11 +164,21 @@ func (c *Cli) GenerateManPage(w io.Writer) {
 	// .SH SYNOPSIS
 	if section, ok := c.Documentation["synopsis"]; ok == true {
 		fmt.Fprintf(w, ".SH SYNOPSIS\n%s\n", md2man(section))
+	} else if len(c.paramSpec) > 0 {
+		// NOTE: without a written synopsis use the one described by the parameters
+		fmt.Fprintf(w, ".SH SYNOPSIS\n\\fB%s\\fP %s\n", c.appName, strings.Join(parts[1:], " "))
 	}
 	// .SH DESCRIPTION
 	if section, ok := c.Documentation["description"]; ok == true {
 		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", md2man(section))
 	}
+	// .SH PARAMETERS
+	if len(c.paramSpec) > 0 {
+		fmt.Fprintf(w, ".SH PARAMETERS\n")
+		for _, param := range c.paramSpec {
+			fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", param.Name, param.doc())
+		}
+	}
 
 	if len(c.options) > 0 {
 		fmt.Fprintf(w, ".SH OPTIONS\n")
@@ -178,14 +190,20 @@ func (c *Cli) GenerateManPage(w io.Writer) {
 			fmt.Fprintf(w, "%s", strings.Join(parts, "\n"))
 		}
 		fmt.Fprintf(w, ".TP\nThe following options are supported.\n")
-		keys := []string{}
-		for k, _ := range c.options {
-			keys = append(keys, k)
+		// Display options by group, sorted alphabetically with in a group
+		for _, group := range groupOptions(c.options, c.GNUStyle) {
+			if group.name != "" {
+				fmt.Fprintf(w, ".SS %s\n", group.name)
+			}
+			for _, o := range group.options {
+				fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", o.synopsis(c.GNUStyle), o.doc())
+			}
 		}
-		// Sort the keys alphabetically and display output
-		sort.Strings(keys)
-		for _, k := range keys {
-			fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", k, c.options[k])
+		if note := deprecatedNote(c.options, c.GNUStyle); note != "" {
+			fmt.Fprintf(w, ".PP\n%s\n", note)
+		}
+		if notes := constraintNotes(c.options, c.constraints, c.GNUStyle); len(notes) > 0 {
+			fmt.Fprintf(w, ".PP\n%s\n", strings.Join(notes, "\n.br\n"))
 		}
 	}
 
@@ -201,10 +219,43 @@ func (c *Cli) GenerateManPage(w io.Writer) {
 		// Sort the keys alphabetically and display output
 		sort.Strings(keys)
 		for _, k := range keys {
-			fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", k, c.env[k].Usage)
+			fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", k, c.env[k].doc(c.GNUStyle))
+		}
+	}
+
+	// .SH VERBS
+	if len(c.verbs) > 0 {
+		fmt.Fprintf(w, ".SH VERBS\n")
+		keys := []string{}
+		for k := range c.verbs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := c.verbs[k]
+			v.inherit()
+			fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", k, v.Usage)
+			if len(v.options) > 0 {
+				fmt.Fprintf(w, ".RS\n")
+				for _, group := range groupOptions(v.options, v.GNUStyle) {
+					if group.name != "" {
+						fmt.Fprintf(w, ".PP\n%s\n", group.name)
+					}
+					for _, o := range group.options {
+						fmt.Fprintf(w, ".TP\n\\fB%s\\fP\n%s\n", o.synopsis(v.GNUStyle), o.doc())
+					}
+				}
+				fmt.Fprintf(w, ".RE\n")
+			}
 		}
 	}
 
+	// .SH FILES
+	if c.config != nil {
+		location, doc := c.configDoc()
+		fmt.Fprintf(w, ".SH FILES\n.TP\n\\fB%s\\fP\n%s\n", location, doc)
+	}
+
 	// .SH EXAMPLES
 	if section, ok := c.Documentation["examples"]; ok == true {
 		//FIXME: Need to convert Markdown of examples into nroff with
@@ -13,6 +13,7 @@ import (
 // Documentation is based on the application's metadata like app name,
 // version, options, actions, etc.
 func (c *Cli) GenerateMarkdown(w io.Writer) {
+	c.applyNegatable()
 	var parts []string
 	parts = append(parts, c.appName)
 
@@ -20,10 +21,11 @@ func (c *Cli) GenerateMarkdown(w io.Writer) {
 		parts = append(parts, "[OPTIONS]")
 	}
 	// NOTE: setup explicit parameter documentation
-	if len(c.params) > 0 {
-		parts = append(parts, c.params...)
+	params := c.paramNames()
+	if len(params) > 0 {
+		parts = append(parts, params...)
 	}
-	if len(c.verbs) > 0 && len(c.params) == 0 {
+	if len(c.verbs) > 0 && len(params) == 0 {
 		if c.VerbsRequired {
 			parts = append(parts, "VERB")
 		} else {
@@ -38,7 +40,7 @@ func (c *Cli) GenerateMarkdown(w io.Writer) {
 		}
 		// Check for verb params
 		for _, verb := range c.verbs {
-			if len(verb.params) > 0 {
+			if len(verb.paramNames()) > 0 {
 				parts = append(parts, "[VERB PARAMETERS...]")
 				break
 			}
@@ -54,6 +56,16 @@ func (c *Cli) GenerateMarkdown(w io.Writer) {
 		fmt.Fprintf(w, "DESCRIPTION\n-----------\n\n%s\n\n", section)
 	}
 
+	if len(c.paramSpec) > 0 {
+		fmt.Fprintf(w, "PARAMETERS\n----------\n\n")
+		padding := paramPadding(c.paramSpec)
+		fmt.Fprintf(w, "```\n")
+		for _, param := range c.paramSpec {
+			fmt.Fprintf(w, "    %s  %s\n", padRight(param.Name, " ", padding), param.doc())
+		}
+		fmt.Fprintf(w, "```\n\n")
+	}
+
 	if len(c.env) > 0 {
 		fmt.Fprintf(w, "ENVIRONMENT\n-----------\n\n")
 		if len(c.options) > 0 {
@@ -71,7 +83,7 @@ func (c *Cli) GenerateMarkdown(w io.Writer) {
 		sort.Strings(keys)
 		fmt.Fprintf(w, "```\n")
 		for _, k := range keys {
-			fmt.Fprintf(w, "    %s  # %s\n", padRight(k, " ", padding), c.env[k].Usage)
+			fmt.Fprintf(w, "    %s  # %s\n", padRight(k, " ", padding), c.env[k].doc(c.GNUStyle))
 		}
 		fmt.Fprintf(w, "```\n\n")
 	}
@@ -86,22 +98,61 @@ func (c *Cli) GenerateMarkdown(w io.Writer) {
 		if len(parts) > 0 {
 			fmt.Fprintf(w, "%s\n\n", strings.Join(parts, " "))
 		}
+		padding := optionPadding(c.options, c.GNUStyle)
+		// Display options by group, sorted alphabetically with in a group
+		for i, group := range groupOptions(c.options, c.GNUStyle) {
+			if group.name != "" {
+				if i > 0 {
+					fmt.Fprintf(w, "\n")
+				}
+				fmt.Fprintf(w, "### %s\n\n", group.name)
+			}
+			fmt.Fprintf(w, "```\n")
+			for _, o := range group.options {
+				fmt.Fprintf(w, "    %s  %s\n", padRight(o.synopsis(c.GNUStyle), " ", padding), o.doc())
+			}
+			fmt.Fprintf(w, "```\n")
+		}
+		if note := deprecatedNote(c.options, c.GNUStyle); note != "" {
+			fmt.Fprintf(w, "\n%s\n", note)
+		}
+		if notes := constraintNotes(c.options, c.constraints, c.GNUStyle); len(notes) > 0 {
+			fmt.Fprintf(w, "\n+ %s\n", strings.Join(notes, "\n+ "))
+		}
+		fmt.Fprintf(w, "\n\n")
+	}
+
+	if c.config != nil {
+		location, doc := c.configDoc()
+		fmt.Fprintf(w, "FILES\n-----\n\n`%s`\n\n%s\n\n", location, doc)
+	}
+
+	if len(c.verbs) > 0 {
+		fmt.Fprintf(w, "VERBS\n-----\n\n")
 		keys := []string{}
-		padding := 0
-		for k, _ := range c.options {
+		for k := range c.verbs {
 			keys = append(keys, k)
-			if len(k) > padding {
-				padding = len(k) + 1
-			}
 		}
-		// Sort the keys alphabetically and display output
 		sort.Strings(keys)
-		fmt.Fprintf(w, "```\n")
 		for _, k := range keys {
-			fmt.Fprintf(w, "    %s  %s\n", padRight(k, " ", padding), c.options[k])
+			v := c.verbs[k]
+			v.inherit()
+			fmt.Fprintf(w, "### %s\n\n%s\n\n", k, v.Usage)
+			if len(v.options) > 0 {
+				padding := optionPadding(v.options, v.GNUStyle)
+				for _, group := range groupOptions(v.options, v.GNUStyle) {
+					if group.name != "" {
+						fmt.Fprintf(w, "#### %s\n\n", group.name)
+					}
+					fmt.Fprintf(w, "```\n")
+					for _, o := range group.options {
+						fmt.Fprintf(w, "    %s  %s\n", padRight(o.synopsis(v.GNUStyle), " ", padding), o.doc())
+					}
+					fmt.Fprintf(w, "```\n\n")
+				}
+			}
 		}
-		fmt.Fprintf(w, "```\n")
-		fmt.Fprintf(w, "\n\n")
+		fmt.Fprintf(w, "\n")
 	}
 
 	if section, ok := c.Documentation["examples"]; ok == true {
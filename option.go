@@ -0,0 +1,470 @@
+// option.go - describes the options associated with a Cli or Verb
+// and formats them for documentation.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Option describes an option associated with a Cli or Verb, e.g. the
+// names "o" and "output" for the option documented as "-o, -output".
+type Option struct {
+	// Names holds the option names without dashes, e.g. "o", "output"
+	Names []string
+	// Type is the type of the option's value, e.g. bool, int, string,
+	// []string, choice, time.Duration
+	Type string
+	// Default holds the default value as a string
+	Default string
+	// Value is the flag.Value shared by each of the option's names,
+	// Value.String() returns the current value
+	Value flag.Value
+	// Usage is the option's doc string
+	Usage string
+	// Placeholder, if set, names the value expected in the documentation
+	// (e.g. FILE in "-o, -output FILE"), otherwise one is derived from
+	// the usage or Type.
+	Placeholder string
+	// Group is the name of the group the option is documented with
+	Group string
+	// Hidden is true if the option is accepted but not documented
+	Hidden bool
+	// Deprecated, if not an empty string, names the option's replacement
+	// or explains why it was retired. Deprecated options are accepted
+	// but a warning is written when used.
+	Deprecated string
+	// Required is true if the option must be given
+	Required bool
+	// Order is the position the option was declared in
+	Order int
+	// Source is where the option's current value came from
+	Source Source
+	// Secret is true if the value is redacted when the configuration is
+	// displayed and the default is left out of the documentation
+	Secret bool
+	// Negatable is true if a boolean option also accepts "no-" forms of
+	// its long names, e.g. "-no-color", documented as "-[no-]color"
+	Negatable bool
+	// EnvVar names the environment variable that also sets the option,
+	// e.g. DATASET for "-dataset". The option overrides the variable.
+	EnvVar string
+
+	// standard is true for options added by AddStandardOptions
+	standard bool
+	// info is true for options requesting information, e.g. -help added
+	// by AddStandardOptions, required options are not checked if given
+	info bool
+}
+
+// String returns the current value of the option
+func (o *Option) String() string {
+	if o.Value == nil {
+		return ""
+	}
+	return o.Value.String()
+}
+
+// Choices returns the allowed values of a choice option, nil otherwise
+func (o *Option) Choices() []string {
+	if cv, ok := o.Value.(choicesValue); ok == true {
+		return cv.Choices()
+	}
+	return nil
+}
+
+// newOption creates an Option for the names and flag.Value
+func newOption(ops []string, value flag.Value, usage string, order int) *Option {
+	return &Option{
+		Names:   ops,
+		Type:    valueType(value),
+		Default: value.String(),
+		Value:   value,
+		Usage:   usage,
+		Order:   order,
+	}
+}
+
+// typedValue may be implemented by a flag.Value to report its type name
+type typedValue interface {
+	Type() string
+}
+
+// valueType returns the type name for a flag.Value
+func valueType(value flag.Value) string {
+	switch value.(type) {
+	case *boolValue:
+		return "bool"
+	case *intValue:
+		return "int"
+	case *int64Value:
+		return "int64"
+	case *uintValue:
+		return "uint"
+	case *uint64Value:
+		return "uint64"
+	case *stringValue:
+		return "string"
+	case *float64Value:
+		return "float64"
+	case *durationValue:
+		return "time.Duration"
+	case *counterValue:
+		return "counter"
+	case *timeValue:
+		return "time.Time"
+	case *stringSliceValue:
+		return "[]string"
+	case *intSliceValue:
+		return "[]int"
+	case *choiceValue:
+		return "choice"
+	case funcValue:
+		return "func"
+	}
+	if tv, ok := value.(typedValue); ok == true {
+		return tv.Type()
+	}
+	return "value"
+}
+
+// label returns the option names as documented, e.g. "-o, -output",
+// or if gnu is true "-o, --output".
+func (o *Option) label(gnu bool) string {
+	if o.Negatable == false {
+		return opsLabel(o.Names, gnu)
+	}
+	parts := []string{}
+	for _, op := range o.Names {
+		if len(op) > 1 {
+			op = "[no-]" + op
+		}
+		parts = append(parts, opsLabel([]string{op}, gnu && len(op) > 1))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// synopsis returns the option label followed by a placeholder for the
+// value expected, e.g. "-o, -output FILE".
+func (o *Option) synopsis(gnu bool) string {
+	if name, _ := o.unquoteUsage(); name != "" {
+		return o.label(gnu) + " " + name
+	}
+	return o.label(gnu)
+}
+
+// unquoteUsage extracts a back-quoted name from the usage string and
+// returns it and the un-quoted usage, e.g. "write output to `FILE`"
+// returns "FILE", "write output to FILE". This follows the convention
+// of the flag package. If there is no back-quoted name a placeholder
+// based on the value's type is returned, e.g. "INT". Options that don't
+// require a value (e.g. bool options) return an empty placeholder.
+func (o *Option) unquoteUsage() (string, string) {
+	usage := o.Usage
+	if o.Placeholder != "" {
+		return o.Placeholder, strings.Replace(usage, "`", "", -1)
+	}
+	if start := strings.Index(usage, "`"); start >= 0 {
+		if end := strings.Index(usage[start+1:], "`"); end >= 0 {
+			end += start + 1
+			name := usage[start+1 : end]
+			return name, usage[:start] + name + usage[end+1:]
+		}
+	}
+	return valuePlaceholder(o.Value), usage
+}
+
+// valuePlaceholder returns a placeholder name for the type of value
+func valuePlaceholder(value flag.Value) string {
+	if bf, ok := value.(boolFlag); ok == true && bf.IsBoolFlag() {
+		return ""
+	}
+	switch v := value.(type) {
+	case *intValue, *int64Value, *intSliceValue:
+		return "INT"
+	case *uintValue, *uint64Value:
+		return "UINT"
+	case *float64Value:
+		return "FLOAT"
+	case *durationValue:
+		return "DURATION"
+	case *timeValue:
+		return "TIME"
+	case *pathValue:
+		if v.kind == dirKind {
+			return "DIR"
+		}
+		return "FILE"
+	case *stringValue, *stringSliceValue:
+		return "STRING"
+	case *choiceValue:
+		return "CHOICE"
+	}
+	return "VALUE"
+}
+
+// isZeroValue returns true if the default value is the zero value
+// for its type, e.g. "", "0", "false" or "0s".
+func isZeroValue(defValue string) bool {
+	switch defValue {
+	case "", "0", "false", "0s":
+		return true
+	}
+	return false
+}
+
+// choicesValue is implemented by option values limited to a set of
+// allowed values.
+type choicesValue interface {
+	Choices() []string
+}
+
+// doc returns the usage along with any notes about the option's value
+// e.g. if the option may be repeated or its default value.
+func (o *Option) doc() string {
+	_, usage := o.unquoteUsage()
+	parts := []string{usage}
+	if cv, ok := o.Value.(choicesValue); ok == true {
+		parts = append(parts, fmt.Sprintf("(one of %s)", strings.Join(cv.Choices(), "|")))
+	}
+	if _, ok := o.Value.(*timeValue); ok == true {
+		parts = append(parts, fmt.Sprintf("(%s)", TimeFormats))
+	}
+	if _, ok := o.Value.(*counterValue); ok == true {
+		parts = append(parts, "(repeatable)")
+	}
+	if sv, ok := o.Value.(sliceValue); ok == true {
+		if sep := sv.separator(); sep != "" {
+			parts = append(parts, fmt.Sprintf("(repeatable, values separated by %q)", sep))
+		} else {
+			parts = append(parts, "(repeatable)")
+		}
+	}
+	if isZeroValue(o.Default) == false && o.Secret == false {
+		switch o.Value.(type) {
+		case *stringValue, *choiceValue, *stringSliceValue, *intSliceValue, *timeValue, *pathValue:
+			parts = append(parts, fmt.Sprintf("(default %q)", o.Default))
+		default:
+			parts = append(parts, fmt.Sprintf("(default %s)", o.Default))
+		}
+	}
+	if o.EnvVar != "" {
+		parts = append(parts, fmt.Sprintf("(env %s)", o.EnvVar))
+	}
+	if o.Required {
+		parts = append(parts, "(required)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// optionDocs returns a map of option labels (e.g. "-o, -output") and
+// their usage as declared. The rendered help adds the placeholders and
+// notes, see synopsis() and doc().
+func optionDocs(options []*Option, gnu bool) map[string]string {
+	docs := map[string]string{}
+	for _, o := range options {
+		if o.Hidden {
+			continue
+		}
+		docs[opsLabel(o.Names, gnu)] = o.Usage
+	}
+	return docs
+}
+
+// optionGroup holds the options documented under a group name
+type optionGroup struct {
+	name    string
+	options []*Option
+}
+
+// groupOptions returns the documented options organized by group, hidden
+// and deprecated options are left out. Groups are in the
+// order they were first declared and options with in a group are sorted
+// alphabetically by their synopsis.
+func groupOptions(options []*Option, gnu bool) []*optionGroup {
+	groups := []*optionGroup{}
+	lookup := map[string]*optionGroup{}
+	for _, o := range options {
+		if o.Hidden || o.Deprecated != "" {
+			continue
+		}
+		group, ok := lookup[o.Group]
+		if ok == false {
+			group = &optionGroup{name: o.Group}
+			lookup[o.Group] = group
+			groups = append(groups, group)
+		}
+		group.options = append(group.options, o)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group.options, func(i, j int) bool {
+			return group.options[i].synopsis(gnu) < group.options[j].synopsis(gnu)
+		})
+	}
+	return groups
+}
+
+// replacement returns the text describing a deprecated option's
+// replacement, e.g. "use -package instead".
+func replacement(options []*Option, o *Option, gnu bool) string {
+	if r := findOption(options, o.Deprecated); r != nil {
+		return fmt.Sprintf("use %s instead", opsLabel([]string{r.longName()}, gnu))
+	}
+	return o.Deprecated
+}
+
+// deprecationWarning returns the warning written when a deprecated
+// option is used.
+func deprecationWarning(options []*Option, o *Option, label string, gnu bool) string {
+	return fmt.Sprintf("WARNING: %q is deprecated, %s", label, replacement(options, o, gnu))
+}
+
+// deprecatedNote returns a note listing the deprecated options and their
+// replacements, e.g. "Deprecated: -p (use -package instead)". An empty
+// string is returned if there are none.
+func deprecatedNote(options []*Option, gnu bool) string {
+	parts := []string{}
+	for _, o := range options {
+		if o.Deprecated != "" && o.Hidden == false {
+			parts = append(parts, fmt.Sprintf("%s (%s)", o.label(gnu), replacement(options, o, gnu)))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Deprecated: " + strings.Join(parts, ", ")
+}
+
+// setHidden marks the named options as hidden
+func setHidden(options []*Option, names []string) error {
+	for _, name := range names {
+		o := findOption(options, name)
+		if o == nil {
+			return fmt.Errorf("%q is an unsupported option", name)
+		}
+		o.Hidden = true
+	}
+	return nil
+}
+
+// setDeprecated marks the named option as deprecated
+func setDeprecated(options []*Option, name string, replacedBy string) error {
+	o := findOption(options, name)
+	if o == nil {
+		return fmt.Errorf("%q is an unsupported option", name)
+	}
+	if replacedBy == "" {
+		replacedBy = "it will be removed in a future release"
+	}
+	o.Deprecated = replacedBy
+	return nil
+}
+
+// setNegatable adds the "no-" forms of the long names of the boolean
+// options to fs.
+func setNegatable(fs *flag.FlagSet, options []*Option, names []string) error {
+	for _, name := range names {
+		o := findOption(options, name)
+		if o == nil {
+			return fmt.Errorf("%q is an unsupported option", name)
+		}
+		if isPlainBool(o.Value) == false {
+			return fmt.Errorf("%q is not a boolean option", name)
+		}
+		if o.Negatable {
+			continue
+		}
+		if len(o.longName()) < 2 {
+			return fmt.Errorf("%q has no long name to negate", name)
+		}
+		for _, op := range o.Names {
+			if len(op) > 1 && fs.Lookup("no-"+op) != nil {
+				return fmt.Errorf("%q is already defined", "no-"+op)
+			}
+		}
+		o.Negatable = true
+		for _, op := range o.Names {
+			if len(op) > 1 {
+				fs.Var(&negatedValue{target: o.Value}, "no-"+op, o.Usage)
+			}
+		}
+	}
+	return nil
+}
+
+// isPlainBool returns true if the value is a boolean, options that only
+// look like one (e.g. counters or -show-config) are not.
+func isPlainBool(value flag.Value) bool {
+	switch value.(type) {
+	case *counterValue, *formatValue:
+		return false
+	}
+	return isBoolValue(value)
+}
+
+// negateBools adds the "no-" forms of every boolean option with a long
+// name. Standard options (e.g. -help), hidden and deprecated options,
+// options named "no-..." and options whose "no-" form is already
+// defined are skipped.
+func negateBools(fs *flag.FlagSet, options []*Option) {
+	for _, o := range options {
+		if o.Negatable || o.standard || o.Hidden || o.Deprecated != "" || isPlainBool(o.Value) == false {
+			continue
+		}
+		name := o.longName()
+		if len(name) < 2 || strings.HasPrefix(name, "no-") {
+			continue
+		}
+		// NOTE: setNegatable rejects options whose "no-" form is taken
+		setNegatable(fs, options, []string{name})
+	}
+}
+
+// findNegated returns the negatable option for a "no-" name, e.g. "no-color"
+func findNegated(options []*Option, name string) *Option {
+	if strings.HasPrefix(name, "no-") {
+		if o := findOption(options, strings.TrimPrefix(name, "no-")); o != nil && o.Negatable {
+			return o
+		}
+	}
+	return nil
+}
+
+// optionPadding returns the width used to align option doc strings
+func optionPadding(options []*Option, gnu bool) int {
+	padding := 0
+	for _, o := range options {
+		if k := o.synopsis(gnu); len(k) > padding {
+			padding = len(k) + 1
+		}
+	}
+	return padding
+}
+
+// findOption returns the option with a matching name (dashes are
+// ignored, e.g. "-o" matches "o") or nil.
+func findOption(options []*Option, name string) *Option {
+	name = strings.TrimLeft(strings.TrimSpace(name), "-")
+	for _, o := range options {
+		for _, op := range o.Names {
+			if op == name {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// setRequired marks the named options as required
+func setRequired(options []*Option, names []string) error {
+	for _, name := range names {
+		o := findOption(options, name)
+		if o == nil {
+			return fmt.Errorf("%q is an unsupported option", name)
+		}
+		o.Required = true
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// param.go - describes the positional (non-option) parameters of a Cli
+// or Verb so they can be validated, converted and documented.
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Param describes a positional command line parameter, e.g. the
+// VARIABLE_NAME and DIR_HOLDING_ASSETS of pkgassets.
+type Param struct {
+	// Name is used in the USAGE line and errors, e.g. "DIR_HOLDING_ASSETS"
+	Name string
+	// Type is the value type, one of string, int, int64, uint, uint64,
+	// float64, bool, duration, time, file (an existing file), dir (an
+	// existing directory) or writable (a file that can be written).
+	// An empty Type is a string.
+	Type string
+	// Usage describes the parameter
+	Usage string
+	// Required is true if the parameter must be given
+	Required bool
+	// Repeat is true if the parameter may be given more than once. The
+	// repeating parameters must be last and repeat together as a group,
+	// e.g. "VARIABLE_NAME DIR [VARIABLE_NAME DIR ...]".
+	Repeat bool
+}
+
+// paramTypes are the supported values of Param.Type
+var paramTypes = []string{"", "string", "int", "int64", "uint", "uint64", "float64", "bool", "duration", "time", fileKind, dirKind, writableKind}
+
+// checkParamSpec returns an error if params can't be matched against
+// the command line unambiguously.
+func checkParamSpec(params []*Param) error {
+	optional, repeat := "", ""
+	for _, param := range params {
+		if param.Name == "" {
+			return fmt.Errorf("parameter name is missing")
+		}
+		known := false
+		for _, typeName := range paramTypes {
+			if param.Type == typeName {
+				known = true
+				break
+			}
+		}
+		if known == false {
+			return fmt.Errorf("%q has an unsupported type %q", param.Name, param.Type)
+		}
+		switch {
+		case param.Repeat:
+			if optional != "" {
+				return fmt.Errorf("%q can't repeat after optional parameter %q", param.Name, optional)
+			}
+			repeat = param.Name
+		case repeat != "":
+			return fmt.Errorf("%q must come before repeating parameter %q", param.Name, repeat)
+		case param.Required && optional != "":
+			return fmt.Errorf("required parameter %q must come before optional parameter %q", param.Name, optional)
+		case param.Required == false:
+			optional = param.Name
+		}
+	}
+	return nil
+}
+
+// paramGroup returns the fixed parameters and the repeating group
+func paramGroup(params []*Param) ([]*Param, []*Param) {
+	for i, param := range params {
+		if param.Repeat {
+			return params[0:i], params[i:]
+		}
+	}
+	return params, nil
+}
+
+// paramUsage returns the USAGE line parts for params, e.g.
+// "NAME", "[URL]" or "VARIABLE_NAME DIR [VARIABLE_NAME DIR ...]"
+func paramUsage(params []*Param) []string {
+	parts := []string{}
+	fixed, group := paramGroup(params)
+	for _, param := range fixed {
+		if param.Required {
+			parts = append(parts, param.Name)
+		} else {
+			parts = append(parts, "["+param.Name+"]")
+		}
+	}
+	if len(group) > 0 {
+		names := []string{}
+		for _, param := range group {
+			names = append(names, param.Name)
+		}
+		if group[0].Required {
+			parts = append(parts, names...)
+		}
+		parts = append(parts, "["+strings.Join(names, " ")+" ...]")
+	}
+	return parts
+}
+
+// paramPadding returns the width of the longest parameter name
+func paramPadding(params []*Param) int {
+	padding := 0
+	for _, param := range params {
+		if len(param.Name) > padding {
+			padding = len(param.Name) + 1
+		}
+	}
+	return padding
+}
+
+// doc returns the description used in the PARAMETERS section
+func (param *Param) doc() string {
+	parts := []string{}
+	if param.Usage != "" {
+		parts = append(parts, param.Usage)
+	}
+	if param.Type != "" && param.Type != "string" {
+		parts = append(parts, fmt.Sprintf("(%s)", param.Type))
+	}
+	if param.Repeat {
+		parts = append(parts, "(repeatable)")
+	}
+	if param.Required == false {
+		parts = append(parts, "(optional)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// convert returns s converted to the parameter's type
+func (param *Param) convert(s string) (interface{}, error) {
+	var err error
+	switch param.Type {
+	case "int":
+		var i int
+		err = newIntValue(0, &i).Set(s)
+		return i, err
+	case "int64":
+		var i int64
+		err = newInt64Value(0, &i).Set(s)
+		return i, err
+	case "uint":
+		var i uint
+		err = newUintValue(0, &i).Set(s)
+		return i, err
+	case "uint64":
+		var i uint64
+		err = newUint64Value(0, &i).Set(s)
+		return i, err
+	case "float64":
+		var f float64
+		err = newFloat64Value(0, &f).Set(s)
+		return f, err
+	case "bool":
+		var b bool
+		err = newBoolValue(false, &b).Set(s)
+		return b, err
+	case "duration":
+		var d time.Duration
+		err = newDurationValue(0, &d).Set(s)
+		return d, err
+	case "time":
+		return ParseTime(s)
+	case fileKind, dirKind, writableKind:
+		return checkPath(s, param.Type)
+	}
+	return s, nil
+}
+
+// matchParams checks args against params returning the converted values
+// by parameter name. Problems are returned as a *ParseError.
+func matchParams(params []*Param, args []string) (map[string][]interface{}, error) {
+	values := map[string][]interface{}{}
+	add := func(param *Param, s string) error {
+		val, err := param.convert(s)
+		if err != nil {
+			return &ParseError{Kind: InvalidValue, Option: param.Name, Value: s, Err: err}
+		}
+		values[param.Name] = append(values[param.Name], val)
+		return nil
+	}
+	fixed, group := paramGroup(params)
+	missing := []string{}
+	i := 0
+	for _, param := range fixed {
+		if i >= len(args) {
+			if param.Required {
+				missing = append(missing, param.Name)
+			}
+			continue
+		}
+		if err := add(param, args[i]); err != nil {
+			return nil, err
+		}
+		i++
+	}
+	if len(missing) > 0 {
+		return nil, &ParseError{Kind: MissingParameter, Missing: missing}
+	}
+	if len(group) == 0 {
+		if i < len(args) {
+			return nil, &ParseError{Kind: UnexpectedParameter, Value: args[i]}
+		}
+		return values, nil
+	}
+	rest := args[i:]
+	if len(rest) == 0 && group[0].Required {
+		for _, param := range group {
+			missing = append(missing, param.Name)
+		}
+	}
+	if n := len(rest) % len(group); n > 0 {
+		for _, param := range group[n:] {
+			missing = append(missing, param.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &ParseError{Kind: MissingParameter, Missing: missing}
+	}
+	for j, s := range rest {
+		if err := add(group[j%len(group)], s); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,241 @@
+// parse.go - walks the command line options for Cli and Verb setting
+// the values held in their flag.FlagSet.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// boolFlag is implemented by flag.Value types that do not require
+// an argument (e.g. -help rather than -help=true).
+type boolFlag interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+// parser holds the state needed to walk a list of command line arguments.
+type parser struct {
+	fs *flag.FlagSet
+	// gnu is true then single dash options may be bundled short
+	// options, e.g. "-qp" is "-q -p" and "-ofile" is "-o file".
+	gnu bool
+	// options describes the options defined in fs
+	options []*Option
+	// eout receives warnings, e.g. when a deprecated option is used
+	eout io.Writer
+	// warned holds the deprecated options already warned about
+	warned map[*Option]bool
+	// interspersed is true then options may follow positional arguments,
+	// e.g. "input.json -o out.json"
+	interspersed bool
+	// stopAt holds arguments that end option processing in interspersed
+	// mode, e.g. the verbs of a Cli so the verb can parse its own options.
+	stopAt map[string]bool
+	// scan when set is called with each option found instead of setting
+	// it, e.g. to find the configuration file before parsing.
+	scan func(o *Option, value string)
+}
+
+// isBoolFlag returns true if the flag does not require an argument
+func isBoolFlag(f *flag.Flag) bool {
+	return isBoolValue(f.Value)
+}
+
+// isBoolValue returns true if the value does not require an argument
+func isBoolValue(value flag.Value) bool {
+	if bf, ok := value.(boolFlag); ok == true {
+		return bf.IsBoolFlag()
+	}
+	return false
+}
+
+// parse walks args setting options found in p.fs. Parsing stops at the
+// first non-option argument or "--", in interspersed mode non-option
+// arguments are collected and parsing continues until "--" or an argument
+// in p.stopAt. The remaining arguments are available from p.fs.Args().
+// Problems are returned as a *ParseError or flag.ErrHelp if -h or -help
+// was requested but not defined.
+func (p *parser) parse(args []string) error {
+	var err error
+	positional := []string{}
+	i := 0
+	for i < len(args) {
+		s := args[i]
+		if len(s) < 2 || s[0] != '-' {
+			if p.interspersed == false || p.stopAt[s] {
+				break
+			}
+			positional = append(positional, s)
+			i++
+			continue
+		}
+		i++
+		if s == "--" {
+			break
+		}
+		if p.gnu && s[1] != '-' && p.isShortCluster(s[1:]) {
+			i, err = p.parseShort(s[1:], args, i)
+		} else {
+			i, err = p.parseLong(s, args, i)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if p.scan != nil {
+		return nil
+	}
+	// NOTE: options are all set, hand the remaining args to the FlagSet
+	// so Args(), Arg() and NArg() work as expected.
+	positional = append(positional, args[i:]...)
+	return p.fs.Parse(append([]string{"--"}, positional...))
+}
+
+// isShortCluster returns true if the single dash argument s should be
+// treated as one or more short options. Long names given with a single
+// dash (e.g. "-help") are still accepted when they are defined.
+func (p *parser) isShortCluster(s string) bool {
+	name := s
+	if pos := strings.Index(name, "="); pos > 0 {
+		name = name[0:pos]
+	}
+	if len(name) > 1 && p.fs.Lookup(name) != nil {
+		return false
+	}
+	return true
+}
+
+// isCounterRun returns true if name repeats the single letter name of a
+// counter option, e.g. "vvv"
+func (p *parser) isCounterRun(name string) bool {
+	if len(name) < 2 || strings.Count(name, name[0:1]) != len(name) {
+		return false
+	}
+	if f := p.fs.Lookup(name[0:1]); f != nil {
+		_, ok := f.Value.(*counterValue)
+		return ok
+	}
+	return false
+}
+
+// unknown returns the error for an undefined option name
+func (p *parser) unknown(label string, name string) error {
+	if name == "h" || name == "help" {
+		return flag.ErrHelp
+	}
+	return &ParseError{Kind: UnknownOption, Option: label, Suggestions: suggestOptions(p.options, name, p.gnu)}
+}
+
+// set assigns value to the named option
+func (p *parser) set(label string, name string, value string) error {
+	o := findOption(p.options, name)
+	if o == nil {
+		o = findNegated(p.options, name)
+	}
+	if p.scan != nil {
+		p.scan(o, value)
+		return nil
+	}
+	if o != nil {
+		o.override(SourceCommandLine)
+	}
+	if err := p.fs.Set(name, value); err != nil {
+		return &ParseError{Kind: InvalidValue, Option: label, Value: value, Err: err}
+	}
+	if o != nil {
+		o.Source = SourceCommandLine
+		if o.Deprecated != "" {
+			p.warn(o, label)
+		}
+	}
+	return nil
+}
+
+// warn writes a warning about a deprecated option once per parse
+func (p *parser) warn(o *Option, label string) {
+	if p.eout == nil || p.warned[o] {
+		return
+	}
+	if p.warned == nil {
+		p.warned = map[*Option]bool{}
+	}
+	p.warned[o] = true
+	fmt.Fprintln(p.eout, deprecationWarning(p.options, o, label, p.gnu))
+}
+
+// parseLong handles an option of the form -name, -name=value, --name,
+// --name=value or "-name value". Returns the position of the next
+// argument to process.
+func (p *parser) parseLong(s string, args []string, i int) (int, error) {
+	name := strings.TrimPrefix(s[1:], "-")
+	if len(name) == 0 || name[0] == '-' || name[0] == '=' {
+		return i, &ParseError{Kind: UnknownOption, Option: s}
+	}
+	value, hasValue := "", false
+	if pos := strings.Index(name, "="); pos > 0 {
+		name, value, hasValue = name[0:pos], name[pos+1:], true
+	}
+	label := s[0:len(s)-len(strings.TrimLeft(s, "-"))] + name
+	f := p.fs.Lookup(name)
+	if f == nil && hasValue == false && p.isCounterRun(name) {
+		// NOTE: "-vvv" is "-v -v -v" for a counter option
+		for _, r := range name {
+			if err := p.set("-"+string(r), string(r), "true"); err != nil {
+				return i, err
+			}
+		}
+		return i, nil
+	}
+	if f == nil {
+		return i, p.unknown(label, name)
+	}
+	if hasValue == false {
+		if isBoolFlag(f) {
+			value = "true"
+		} else if i < len(args) {
+			value = args[i]
+			i++
+		} else {
+			return i, &ParseError{Kind: MissingArgument, Option: label}
+		}
+	}
+	return i, p.set(label, name, value)
+}
+
+// parseShort handles a cluster of single letter options, e.g. "qp"
+// from "-qp". An option needing a value takes the rest of the
+// cluster (e.g. "-ofile") or the next argument (e.g. "-o file").
+// Returns the position of the next argument to process.
+func (p *parser) parseShort(cluster string, args []string, i int) (int, error) {
+	for j, r := range cluster {
+		name := string(r)
+		label := "-" + name
+		f := p.fs.Lookup(name)
+		if f == nil {
+			return i, p.unknown(label, name)
+		}
+		rest := cluster[j+len(name):]
+		if isBoolFlag(f) {
+			if strings.HasPrefix(rest, "=") {
+				return i, p.set(label, name, rest[1:])
+			}
+			if err := p.set(label, name, "true"); err != nil {
+				return i, err
+			}
+			continue
+		}
+		value := strings.TrimPrefix(rest, "=")
+		if rest == "" {
+			if i >= len(args) {
+				return i, &ParseError{Kind: MissingArgument, Option: label}
+			}
+			value = args[i]
+			i++
+		}
+		return i, p.set(label, name, value)
+	}
+	return i, nil
+}
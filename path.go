@@ -0,0 +1,116 @@
+// path.go - option and parameter values naming files and directories,
+// checked when the command line is parsed so programs fail before
+// doing any work.
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// fileKind is an existing file
+	fileKind = "file"
+	// dirKind is an existing directory
+	dirKind = "dir"
+	// writableKind is a file that can be created or overwritten
+	writableKind = "writable"
+)
+
+// ExpandPath expands a leading "~" to the home directory and any
+// $VAR or ${VAR} from the environment, e.g. "~/$DATASET.ds".
+func ExpandPath(s string) string {
+	s = os.ExpandEnv(s)
+	if s == "~" || strings.HasPrefix(s, "~/") || strings.HasPrefix(s, "~"+string(os.PathSeparator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[1:])
+		}
+	}
+	return s
+}
+
+// checkPath expands s and checks it is the kind of path expected,
+// returning the expanded path.
+func checkPath(s string, kind string) (string, error) {
+	if s == "" {
+		return "", errors.New("path is empty")
+	}
+	s = ExpandPath(s)
+	info, err := os.Stat(s)
+	switch kind {
+	case fileKind:
+		if err != nil {
+			return s, errors.New("no such file")
+		}
+		if info.IsDir() {
+			return s, errors.New("is a directory")
+		}
+	case dirKind:
+		if err != nil {
+			return s, errors.New("no such directory")
+		}
+		if info.IsDir() == false {
+			return s, errors.New("not a directory")
+		}
+	case writableKind:
+		if err == nil {
+			if info.IsDir() {
+				return s, errors.New("is a directory")
+			}
+			fp, err := os.OpenFile(s, os.O_WRONLY, 0)
+			if err != nil {
+				return s, errors.New("not writable")
+			}
+			fp.Close()
+			return s, nil
+		}
+		dir := filepath.Dir(s)
+		if info, err := os.Stat(dir); err != nil || info.IsDir() == false {
+			return s, errors.New("no such directory " + dir)
+		}
+		// NOTE: the only portable way to know we can create the file
+		// is to try it.
+		fp, err := os.CreateTemp(dir, ".writable-*")
+		if err != nil {
+			return s, errors.New("directory is not writable")
+		}
+		fp.Close()
+		os.Remove(fp.Name())
+	}
+	return s, nil
+}
+
+// pathValue implements flag.Value for a string naming a file or
+// directory, the path is expanded and checked when set.
+type pathValue struct {
+	p    *string
+	kind string
+}
+
+func newPathValue(val string, kind string, p *string) *pathValue {
+	*p = val
+	return &pathValue{p: p, kind: kind}
+}
+
+func (v *pathValue) Set(s string) error {
+	s, err := checkPath(s, v.kind)
+	if err != nil {
+		return err
+	}
+	*v.p = s
+	return nil
+}
+
+func (v *pathValue) Get() interface{} { return *v.p }
+
+func (v *pathValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return *v.p
+}
+
+// Type returns the kind of path, e.g. "file", "dir" or "writable"
+func (v *pathValue) Type() string { return v.kind }
@@ -0,0 +1,155 @@
+// respfile.go - expands response files, e.g. "@args.txt", into the
+// arguments they hold so long lists of options and identifiers need not
+// be given on the command line.
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// splitWords splits src into words the way a shell would. Words are
+// separated by white space, "#" starts a comment to the end of the line,
+// single quotes are literal, double quotes allow \", \\, \$ and \`
+// escapes and a backslash outside quotes escapes the next character
+// (a backslash before a newline joins the lines).
+func splitWords(src string) ([]string, error) {
+	var word strings.Builder
+	words, inWord, lineNo := []string{}, false, 1
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case ch == ' ', ch == '\t', ch == '\r', ch == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			if ch == '\n' {
+				lineNo++
+			}
+		case ch == '#' && inWord == false:
+			for i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+		case ch == '\'':
+			j := strings.IndexByte(src[i+1:], '\'')
+			if j < 0 {
+				return nil, fmt.Errorf("line %d, missing closing quote", lineNo)
+			}
+			quoted := src[i+1 : i+1+j]
+			word.WriteString(quoted)
+			lineNo += strings.Count(quoted, "\n")
+			inWord = true
+			i += j + 1
+		case ch == '"':
+			start, closed := lineNo, false
+			for i++; i < len(src); i++ {
+				if src[i] == '"' {
+					closed = true
+					break
+				}
+				escaped := false
+				if src[i] == '\\' && i+1 < len(src) && strings.IndexByte("\"\\$`\n", src[i+1]) >= 0 {
+					i, escaped = i+1, true
+				}
+				if src[i] == '\n' {
+					lineNo++
+					if escaped {
+						continue
+					}
+				}
+				word.WriteByte(src[i])
+			}
+			if closed == false {
+				return nil, fmt.Errorf("line %d, missing closing quote", start)
+			}
+			inWord = true
+		case ch == '\\':
+			if i+1 < len(src) {
+				i++
+				if src[i] == '\n' {
+					lineNo++
+					continue
+				}
+				word.WriteByte(src[i])
+				inWord = true
+			}
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ExpandResponseFiles replaces each argument starting with "@" (e.g.
+// "@args.txt") with the words read from the file, see splitWords for
+// the quoting and comments allowed. Response files may name other
+// response files, relative names are read from the directory of the
+// file naming them. A file naming itself, directly or not, is an error.
+// Arguments after "--" are not expanded.
+func ExpandResponseFiles(args []string) ([]string, error) {
+	expanded, _, err := expandResponseFiles(args, "", []string{})
+	return expanded, err
+}
+
+// expandResponseFiles expands args read from a file in dir, including
+// lists the response files being read. Returns true if "--" was seen.
+func expandResponseFiles(args []string, dir string, including []string) ([]string, bool, error) {
+	expanded := []string{}
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...), true, nil
+		}
+		if len(arg) < 2 || arg[0] != '@' {
+			expanded = append(expanded, arg)
+			continue
+		}
+		fName := arg[1:]
+		if dir != "" && filepath.IsAbs(fName) == false {
+			fName = filepath.Join(dir, fName)
+		}
+		absName, err := filepath.Abs(fName)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s, %s", arg, err)
+		}
+		for _, name := range including {
+			if name == absName {
+				return nil, false, fmt.Errorf("%s, response file includes itself", arg)
+			}
+		}
+		src, err := ioutil.ReadFile(fName)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s, %s", arg, err)
+		}
+		words, err := splitWords(string(src))
+		if err != nil {
+			return nil, false, fmt.Errorf("%s, %s", fName, err)
+		}
+		// NOTE: a full slice expression so sibling files don't share including
+		words, stop, err := expandResponseFiles(words, filepath.Dir(fName), append(including[:len(including):len(including)], absName))
+		if err != nil {
+			return nil, false, err
+		}
+		expanded = append(expanded, words...)
+		if stop {
+			return append(expanded, args[i+1:]...), true, nil
+		}
+	}
+	return expanded, false, nil
+}
+
+// expandArgs returns args with any response files expanded if
+// c.ResponseFiles is true.
+func (c *Cli) expandArgs(args []string) ([]string, error) {
+	if c.ResponseFiles == false {
+		return args, nil
+	}
+	return ExpandResponseFiles(args)
+}
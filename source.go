@@ -0,0 +1,87 @@
+// source.go - records where the effective value of an option or
+// environment attribute came from so precedence can be explained.
+package cli
+
+import (
+	"fmt"
+)
+
+// Source identifies where a value came from
+type Source int
+
+const (
+	// SourceDefault is the value declared with the option or attribute
+	SourceDefault Source = iota
+	// SourceEnvironment is a value read from an environment variable
+	SourceEnvironment
+	// SourceConfig is a value read from a configuration file
+	SourceConfig
+	// SourceCommandLine is a value given on the command line
+	SourceCommandLine
+	// SourceDotEnv is a value read from a dotenv file, e.g. ".env"
+	SourceDotEnv
+)
+
+// String returns the source's name, e.g. "command line"
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceEnvironment:
+		return "environment"
+	case SourceConfig:
+		return "config file"
+	case SourceCommandLine:
+		return "command line"
+	case SourceDotEnv:
+		return "dotenv file"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// layeredValue is implemented by option values that accumulate (e.g.
+// []string), replaceOnSet makes the next Set replace the values so a
+// later source overrides rather than adds to an earlier one.
+type layeredValue interface {
+	replaceOnSet()
+}
+
+// override prepares the option to be set from src, values from an
+// earlier source are replaced rather than added to.
+func (o *Option) override(src Source) {
+	if o.Source != src && o.Source != SourceDefault {
+		if lv, ok := o.Value.(layeredValue); ok == true {
+			lv.replaceOnSet()
+		}
+	}
+}
+
+// setFrom sets the option's value from s recording the source
+func (o *Option) setFrom(s string, src Source) error {
+	o.override(src)
+	if err := o.Value.Set(s); err != nil {
+		return err
+	}
+	o.Source = src
+	return nil
+}
+
+// ValueSource returns where the effective value of the named option
+// (e.g. "output") or environment variable (e.g. "DATASET") came from.
+func (c *Cli) ValueSource(name string) (Source, error) {
+	if o := findOption(c.options, name); o != nil {
+		return o.Source, nil
+	}
+	if e, ok := c.env[name]; ok == true {
+		return e.Source, nil
+	}
+	return SourceDefault, fmt.Errorf("%q is an unsupported option or environment variable", name)
+}
+
+// ValueSource returns where the effective value of the named option came from.
+func (v *Verb) ValueSource(name string) (Source, error) {
+	if o := findOption(v.options, name); o != nil {
+		return o.Source, nil
+	}
+	return SourceDefault, fmt.Errorf("%q is an unsupported option", name)
+}
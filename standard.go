@@ -0,0 +1,168 @@
+// standard.go - registers and handles the options every Caltech Library
+// command line program provides, e.g. -help, -version and -output.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// StandardOptions holds the values of the options registered by
+// AddStandardOptions().
+type StandardOptions struct {
+	ShowHelp         bool
+	ShowLicense      bool
+	ShowVersion      bool
+	ShowExamples     bool
+	ShowConfig       bool
+	ConfigFormat     string
+	GenerateMarkdown bool
+	GenerateManPage  bool
+	InputFName       string
+	OutputFName      string
+	Quiet            bool
+
+	// opened is true once c.In and c.Out were opened by HandleStandardOptions
+	opened bool
+}
+
+// AddStandardOptions registers -h/-help, -l/-license, -v/-version,
+// -examples, -i/-input, -o/-output, -quiet, -generate-markdown,
+// -generate-manpage and -show-config in a "Standard Options" group.
+// Options named in skip by their long name (e.g. "input") are left out,
+// a short name (e.g. "v") leaves out only that name. Call
+// HandleStandardOptions() after parsing to act on them.
+func (c *Cli) AddStandardOptions(skip ...string) *StandardOptions {
+	std := &StandardOptions{}
+	skipped := map[string]bool{}
+	for _, name := range skip {
+		for _, op := range splitOps(name) {
+			skipped[op] = true
+		}
+	}
+	// include returns the names not skipped, none if the long name was
+	// skipped, e.g. skipping "v" keeps "version" but "input" drops "-i" too.
+	include := func(names string) string {
+		ops := splitOps(names)
+		if skipped[ops[len(ops)-1]] {
+			return ""
+		}
+		kept := []string{}
+		for _, op := range ops {
+			if skipped[op] == false {
+				kept = append(kept, op)
+			}
+		}
+		return strings.Join(kept, ",")
+	}
+	// markInfo marks the option just added as requesting information
+	markInfo := func() {
+		c.options[len(c.options)-1].info = true
+	}
+	group, added := c.group, len(c.options)
+	c.OptionGroup("Standard Options")
+	if names := include("h,help"); names != "" {
+		c.BoolVar(&std.ShowHelp, names, false, "display help")
+		markInfo()
+	}
+	if names := include("l,license"); names != "" {
+		c.BoolVar(&std.ShowLicense, names, false, "display license")
+		markInfo()
+	}
+	if names := include("v,version"); names != "" {
+		c.BoolVar(&std.ShowVersion, names, false, "display version")
+		markInfo()
+	}
+	if names := include("examples"); names != "" {
+		c.BoolVar(&std.ShowExamples, names, false, "display examples")
+		markInfo()
+	}
+	if names := include("i,input"); names != "" {
+		c.StringVar(&std.InputFName, names, "", "input file name")
+	}
+	if names := include("o,output"); names != "" {
+		c.StringVar(&std.OutputFName, names, "", "output file name")
+	}
+	if names := include("quiet"); names != "" {
+		c.BoolVar(&std.Quiet, names, false, "suppress error messages")
+	}
+	if names := include("generate-markdown"); names != "" {
+		c.BoolVar(&std.GenerateMarkdown, names, false, "generate Markdown documentation")
+		markInfo()
+	}
+	if names := include("generate-manpage"); names != "" {
+		c.BoolVar(&std.GenerateManPage, names, false, "generate man page")
+		markInfo()
+	}
+	if names := include("show-config"); names != "" {
+		c.Var(newFormatValue(&std.ShowConfig, &std.ConfigFormat, []string{"text", "json"}), names, "display the configuration with each value's default and source, -show-config=json for JSON")
+		markInfo()
+	}
+	for _, o := range c.options[added:] {
+		o.standard = true
+	}
+	c.OptionGroup(group)
+	c.standard = std
+	return std
+}
+
+// HandleStandardOptions acts on the standard options once the command
+// line is parsed. It opens c.In and c.Out from -input and -output then
+// writes the documentation, help, license, version or configuration if
+// requested. Returns true if the program should exit, i.e. the
+// information requested was written. Call CloseStandardOptions() when done.
+func (c *Cli) HandleStandardOptions() (bool, error) {
+	std := c.standard
+	if std == nil {
+		return false, fmt.Errorf("standard options were not added")
+	}
+	var err error
+	c.In, err = Open(std.InputFName, os.Stdin)
+	if err != nil {
+		return true, err
+	}
+	c.Out, err = Create(std.OutputFName, os.Stdout)
+	if err != nil {
+		CloseFile(std.InputFName, c.In)
+		return true, err
+	}
+	std.opened = true
+
+	switch {
+	case std.GenerateMarkdown:
+		c.GenerateMarkdown(c.Out)
+	case std.GenerateManPage:
+		c.GenerateManPage(c.Out)
+	case std.ShowHelp || std.ShowExamples:
+		if args := c.Args(); len(args) > 0 {
+			fmt.Fprintln(c.Out, c.Help(args...))
+		} else {
+			c.Usage(c.Out)
+		}
+	case std.ShowLicense:
+		fmt.Fprintln(c.Out, c.License())
+	case std.ShowVersion:
+		fmt.Fprintln(c.Out, c.Version())
+	case std.ShowConfig:
+		return true, c.DumpConfig(c.Out, std.ConfigFormat)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// CloseStandardOptions closes c.In and c.Out if they were opened by
+// HandleStandardOptions() from -input and -output.
+func (c *Cli) CloseStandardOptions() error {
+	std := c.standard
+	if std == nil || std.opened == false {
+		return nil
+	}
+	std.opened = false
+	err := CloseFile(std.InputFName, c.In)
+	if err2 := CloseFile(std.OutputFName, c.Out); err == nil {
+		err = err2
+	}
+	return err
+}
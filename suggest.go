@@ -0,0 +1,107 @@
+// suggest.go - finds the closest matches to a mistyped option or verb
+// so error messages can ask "did you mean ...?".
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the most suggestions included in an error message
+const maxSuggestions = 3
+
+// minInt returns the smaller of a and b
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// editDistance returns the number of single character insertions,
+// deletions, substitutions or transpositions needed to turn a into b.
+func editDistance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+// suggest returns the candidates closest to name, nearest first
+func suggest(name string, candidates []string) []string {
+	type match struct {
+		s        string
+		distance int
+	}
+	matches := []match{}
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		distance := editDistance(name, candidate)
+		if distance <= 2 && distance < len(name) {
+			matches = append(matches, match{s: candidate, distance: distance})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance == matches[j].distance {
+			return matches[i].s < matches[j].s
+		}
+		return matches[i].distance < matches[j].distance
+	})
+	suggestions := []string{}
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].s)
+	}
+	return suggestions
+}
+
+// suggestOptions returns the labels of the options closest to name,
+// hidden and deprecated options are not suggested.
+func suggestOptions(options []*Option, name string, gnu bool) []string {
+	candidates := []string{}
+	labels := map[string]string{}
+	for _, o := range options {
+		if o.Hidden || o.Deprecated != "" {
+			continue
+		}
+		for _, op := range o.Names {
+			candidates = append(candidates, op)
+			labels[op] = opsLabel([]string{op}, gnu)
+		}
+	}
+	suggestions := []string{}
+	for _, op := range suggest(name, candidates) {
+		suggestions = append(suggestions, labels[op])
+	}
+	return suggestions
+}
+
+// didYouMean returns a hint naming the suggestions, e.g.
+// ", did you mean -output?", or an empty string if there are none.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ", did you mean " + strings.Join(suggestions, " or ") + "?"
+}
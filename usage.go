@@ -14,16 +14,18 @@ import (
 // Usage writes a help page to io.Writer provided. Documentation is based on
 // the application's metadata like app name, version, options, actions, etc.
 func (c *Cli) Usage(w io.Writer) {
+	c.applyNegatable()
 	var parts []string
 	parts = append(parts, c.appName)
 	if len(c.options) > 0 {
 		parts = append(parts, "[OPTIONS]")
 	}
 	// Add parts defined by params, verbs, or actions
-	if len(c.params) > 0 {
-		parts = append(parts, c.params...)
+	params := c.paramNames()
+	if len(params) > 0 {
+		parts = append(parts, params...)
 	}
-	if len(c.verbs) > 0 && len(c.params) == 0 {
+	if len(c.verbs) > 0 && len(params) == 0 {
 		if c.VerbsRequired {
 			parts = append(parts, "VERB")
 		} else {
@@ -38,7 +40,7 @@ func (c *Cli) Usage(w io.Writer) {
 		}
 		// Check for verb params
 		for _, verb := range c.verbs {
-			if len(verb.params) > 0 {
+			if len(verb.paramNames()) > 0 {
 				parts = append(parts, "[VERB PARAMETERS...]")
 				break
 			}
@@ -53,6 +55,15 @@ func (c *Cli) Usage(w io.Writer) {
 		fmt.Fprintf(w, "DESCRIPTION\n\n%s\n\n", bytes.TrimSpace(section))
 	}
 
+	if len(c.paramSpec) > 0 {
+		fmt.Fprintf(w, "PARAMETERS\n\n")
+		padding := paramPadding(c.paramSpec)
+		for _, param := range c.paramSpec {
+			fmt.Fprintf(w, "    %s  %s\n", padRight(param.Name, " ", padding), param.doc())
+		}
+		fmt.Fprintf(w, "\n\n")
+	}
+
 	if len(c.env) > 0 {
 		fmt.Fprintf(w, "ENVIRONMENT\n\n")
 		if len(c.options) > 0 {
@@ -69,7 +80,7 @@ func (c *Cli) Usage(w io.Writer) {
 		// Sort the keys alphabetically and display output
 		sort.Strings(keys)
 		for _, k := range keys {
-			fmt.Fprintf(w, "    %s  %s\n", padRight(k, " ", padding), c.env[k].Usage)
+			fmt.Fprintf(w, "    %s  %s\n", padRight(k, " ", padding), c.env[k].doc(c.GNUStyle))
 		}
 		fmt.Fprintf(w, "\n\n")
 	}
@@ -79,22 +90,33 @@ func (c *Cli) Usage(w io.Writer) {
 		if len(c.env) > 0 {
 			fmt.Fprintf(w, "Options will override any corresponding environment settings\n\n")
 		}
-		keys := []string{}
-		padding := 0
-		for k, _ := range c.options {
-			keys = append(keys, k)
-			if len(k) > padding {
-				padding = len(k) + 1
+		padding := optionPadding(c.options, c.GNUStyle)
+		// Display options by group, sorted alphabetically with in a group
+		for i, group := range groupOptions(c.options, c.GNUStyle) {
+			if group.name != "" {
+				if i > 0 {
+					fmt.Fprintf(w, "\n")
+				}
+				fmt.Fprintf(w, "  %s\n\n", group.name)
+			}
+			for _, o := range group.options {
+				fmt.Fprintf(w, "    %s  %s\n", padRight(o.synopsis(c.GNUStyle), " ", padding), o.doc())
 			}
 		}
-		// Sort the keys alphabetically and display output
-		sort.Strings(keys)
-		for _, k := range keys {
-			fmt.Fprintf(w, "    %s  %s\n", padRight(k, " ", padding), c.options[k])
+		if note := deprecatedNote(c.options, c.GNUStyle); note != "" {
+			fmt.Fprintf(w, "\n    %s\n", note)
+		}
+		if notes := constraintNotes(c.options, c.constraints, c.GNUStyle); len(notes) > 0 {
+			fmt.Fprintf(w, "\n    %s\n", strings.Join(notes, "\n    "))
 		}
 		fmt.Fprintf(w, "\n\n")
 	}
 
+	if c.config != nil {
+		location, doc := c.configDoc()
+		fmt.Fprintf(w, "FILES\n\n    %s\n        %s\n\n\n", location, doc)
+	}
+
 	if len(c.verbs) > 0 {
 		fmt.Fprintf(w, "VERBS\n\n")
 		keys := []string{}
@@ -110,17 +132,24 @@ func (c *Cli) Usage(w io.Writer) {
 		for _, k := range keys {
 			usage := c.verbs[k].Usage
 			fmt.Fprintf(w, "    %s  %s\n", padRight(k, " ", padding), usage)
-			if len(c.verbs[k].params) > 0 {
+			if params := c.verbs[k].paramNames(); len(params) > 0 {
 				if len(c.verbs[k].options) == 0 {
-					fmt.Fprintf(w, "    %s   `%s %s %s`\n", padRight("", " ", padding), c.appName, k, strings.Join(c.verbs[k].params, " "))
+					fmt.Fprintf(w, "    %s   `%s %s %s`\n", padRight("", " ", padding), c.appName, k, strings.Join(params, " "))
 				} else {
-					fmt.Fprintf(w, "    %s   `%s %s [VERB OPTIONS] %s`\n", padRight("", " ", padding), c.appName, k, strings.Join(c.verbs[k].params, " "))
+					fmt.Fprintf(w, "    %s   `%s %s [VERB OPTIONS] %s`\n", padRight("", " ", padding), c.appName, k, strings.Join(params, " "))
 				}
 			}
-			if len(c.verbs[k].options) > 0 {
+			if v := c.verbs[k]; len(v.options) > 0 {
+				v.inherit()
 				fmt.Fprintf(w, "    %s  verb options:\n", padRight("", " ", padding))
-				for op, desc := range c.verbs[k].options {
-					fmt.Fprintf(w, "    %s  %s     %s\n", padRight("", " ", padding), padRight(op, " ", padding), desc)
+				optPadding := optionPadding(v.options, v.GNUStyle)
+				for _, group := range groupOptions(v.options, v.GNUStyle) {
+					if group.name != "" {
+						fmt.Fprintf(w, "    %s    %s\n", padRight("", " ", padding), group.name)
+					}
+					for _, o := range group.options {
+						fmt.Fprintf(w, "    %s      %s  %s\n", padRight("", " ", padding), padRight(o.synopsis(v.GNUStyle), " ", optPadding), o.doc())
+					}
 				}
 			}
 			fmt.Fprintf(w, "\n")
@@ -146,7 +175,7 @@ func (c *Cli) Usage(w io.Writer) {
 		if len(keys) > 0 {
 			// Sort the keys alphabetically and display output
 			sort.Strings(keys)
-			fmt.Fprintf(w, "See %s -help TOPIC for topics - %s\n\n", c.appName, strings.Join(keys, ", "))
+			fmt.Fprintf(w, "See %s %s TOPIC for topics - %s\n\n", c.appName, opsLabel([]string{"help"}, c.GNUStyle), strings.Join(keys, ", "))
 		}
 	}
 
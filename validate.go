@@ -0,0 +1,172 @@
+// validate.go - checks the options given to a Cli or Verb once the
+// command line has been parsed, e.g. that required options are set,
+// constraints are met and the positional parameters are as expected.
+package cli
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+)
+
+// visited returns a map of the option names set in fs
+func visited(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// isInfoRequest returns true if an option requesting information (e.g.
+// -help or -version added by AddStandardOptions) was given. When one is
+// given required options are not enforced so the program can display
+// the information requested.
+func isInfoRequest(options []*Option, set map[string]bool) bool {
+	for _, o := range options {
+		if o.info == false {
+			continue
+		}
+		for _, name := range o.Names {
+			if set[name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSet returns true if the option was set by any of its names or
+// from another source, e.g. an environment variable bound to it.
+func (o *Option) isSet(set map[string]bool) bool {
+	if o.Source != SourceDefault {
+		return true
+	}
+	for _, name := range o.Names {
+		if set[name] || (o.Negatable && set["no-"+name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// longName returns the longest of the option's names, e.g. "output"
+// for "o,output".
+func (o *Option) longName() string {
+	longest := ""
+	for _, name := range o.Names {
+		if len(name) > len(longest) {
+			longest = name
+		}
+	}
+	return longest
+}
+
+// missingOptions returns the labels of required options not in set
+func missingOptions(options []*Option, set map[string]bool, gnu bool) []string {
+	missing := []string{}
+	for _, o := range options {
+		if o.Required && o.isSet(set) == false {
+			missing = append(missing, opsLabel([]string{o.longName()}, gnu))
+		}
+	}
+	return missing
+}
+
+// validate applies any implied options then checks required options,
+// constraints and positional parameters.
+func (c *Cli) validate() error {
+	if err := applyImplied(c.FlagSet, c.options, c.constraints); err != nil {
+		return err
+	}
+	if err := c.checkRequired(); err != nil {
+		return err
+	}
+	set := visited(c.FlagSet)
+	if err := checkConstraints(c.options, c.constraints, set, c.GNUStyle); err != nil {
+		return err
+	}
+	return c.checkParams(set)
+}
+
+// checkRequired returns a *ParseError listing all the required options
+// and environment variables which were not set.
+func (c *Cli) checkRequired() error {
+	set := visited(c.FlagSet)
+	if isInfoRequest(c.options, set) {
+		return nil
+	}
+	missing := missingOptions(c.options, set, c.GNUStyle)
+	keys := []string{}
+	for k, e := range c.env {
+		// NOTE: an attribute bound to an option is also set by the option
+		if e.Required && e.Source == SourceDefault && strings.TrimSpace(os.Getenv(k)) == "" && (e.option == nil || e.option.Source == SourceDefault) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	missing = append(missing, keys...)
+	if len(missing) > 0 {
+		return &ParseError{Kind: MissingRequired, Missing: missing}
+	}
+	return nil
+}
+
+// validate applies any implied options then checks required options,
+// constraints and positional parameters.
+func (v *Verb) validate() error {
+	if err := applyImplied(v.FlagSet, v.options, v.constraints); err != nil {
+		return err
+	}
+	if err := v.checkRequired(); err != nil {
+		return err
+	}
+	set := visited(v.FlagSet)
+	if err := checkConstraints(v.options, v.constraints, set, v.GNUStyle); err != nil {
+		return err
+	}
+	return v.checkParams(set)
+}
+
+// checkRequired returns a *ParseError listing all the required options
+// which were not set.
+func (v *Verb) checkRequired() error {
+	set := visited(v.FlagSet)
+	if isInfoRequest(v.options, set) {
+		return nil
+	}
+	missing := missingOptions(v.options, set, v.GNUStyle)
+	if len(missing) > 0 {
+		return &ParseError{Kind: MissingRequired, Missing: missing}
+	}
+	return nil
+}
+
+// checkParams matches the remaining arguments against the positional
+// parameters unless help, version, etc. was requested.
+func (c *Cli) checkParams(set map[string]bool) error {
+	if len(c.paramSpec) == 0 || isInfoRequest(c.options, set) {
+		return nil
+	}
+	values, err := matchParams(c.paramSpec, c.Args())
+	if err != nil {
+		return err
+	}
+	c.paramValues = values
+	return nil
+}
+
+// checkParams matches the remaining arguments against the positional
+// parameters unless help was requested.
+func (v *Verb) checkParams(set map[string]bool) error {
+	if len(v.paramSpec) == 0 || isInfoRequest(v.options, set) {
+		return nil
+	}
+	values, err := matchParams(v.paramSpec, v.Args())
+	if err != nil {
+		return err
+	}
+	v.paramValues = values
+	return nil
+}
@@ -0,0 +1,562 @@
+// values.go - implements the flag.Value types used by the option
+// funcs of Cli and Verb (e.g. BoolVar, StringVar).
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errParse is returned by Set if a value fails to parse
+	errParse = errors.New("parse error")
+	// errRange is returned by Set if a value is out of range
+	errRange = errors.New("value out of range")
+)
+
+// numError simplifies the errors returned by strconv, like the flag package
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok == true {
+		if ne.Err == strconv.ErrSyntax {
+			return errParse
+		}
+		if ne.Err == strconv.ErrRange {
+			return errRange
+		}
+	}
+	return err
+}
+
+// boolValue implements flag.Value for a bool
+type boolValue bool
+
+func newBoolValue(val bool, p *bool) *boolValue {
+	*p = val
+	return (*boolValue)(p)
+}
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		err = errParse
+	}
+	*b = boolValue(v)
+	return err
+}
+
+func (b *boolValue) Get() interface{} { return bool(*b) }
+
+func (b *boolValue) String() string { return strconv.FormatBool(bool(*b)) }
+
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+// intValue implements flag.Value for an int
+type intValue int
+
+func newIntValue(val int, p *int) *intValue {
+	*p = val
+	return (*intValue)(p)
+}
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		err = numError(err)
+	}
+	*i = intValue(v)
+	return err
+}
+
+func (i *intValue) Get() interface{} { return int(*i) }
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+
+// int64Value implements flag.Value for an int64
+type int64Value int64
+
+func newInt64Value(val int64, p *int64) *int64Value {
+	*p = val
+	return (*int64Value)(p)
+}
+
+func (i *int64Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		err = numError(err)
+	}
+	*i = int64Value(v)
+	return err
+}
+
+func (i *int64Value) Get() interface{} { return int64(*i) }
+
+func (i *int64Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// uintValue implements flag.Value for a uint
+type uintValue uint
+
+func newUintValue(val uint, p *uint) *uintValue {
+	*p = val
+	return (*uintValue)(p)
+}
+
+func (i *uintValue) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		err = numError(err)
+	}
+	*i = uintValue(v)
+	return err
+}
+
+func (i *uintValue) Get() interface{} { return uint(*i) }
+
+func (i *uintValue) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// uint64Value implements flag.Value for a uint64
+type uint64Value uint64
+
+func newUint64Value(val uint64, p *uint64) *uint64Value {
+	*p = val
+	return (*uint64Value)(p)
+}
+
+func (i *uint64Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		err = numError(err)
+	}
+	*i = uint64Value(v)
+	return err
+}
+
+func (i *uint64Value) Get() interface{} { return uint64(*i) }
+
+func (i *uint64Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// stringValue implements flag.Value for a string
+type stringValue string
+
+func newStringValue(val string, p *string) *stringValue {
+	*p = val
+	return (*stringValue)(p)
+}
+
+func (s *stringValue) Set(val string) error {
+	*s = stringValue(val)
+	return nil
+}
+
+func (s *stringValue) Get() interface{} { return string(*s) }
+
+func (s *stringValue) String() string { return string(*s) }
+
+// float64Value implements flag.Value for a float64
+type float64Value float64
+
+func newFloat64Value(val float64, p *float64) *float64Value {
+	*p = val
+	return (*float64Value)(p)
+}
+
+func (f *float64Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		err = numError(err)
+	}
+	*f = float64Value(v)
+	return err
+}
+
+func (f *float64Value) Get() interface{} { return float64(*f) }
+
+func (f *float64Value) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+
+// durationValue implements flag.Value for a time.Duration
+type durationValue time.Duration
+
+func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
+	*p = val
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		err = errParse
+	}
+	*d = durationValue(v)
+	return err
+}
+
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+
+func (d *durationValue) String() string { return (*time.Duration)(d).String() }
+
+// counterValue implements flag.Value for an int raised each time the
+// option is given, e.g. "-v -v -v" or "-vvv" is 3. An explicit value
+// (e.g. "-v=2") sets the count.
+type counterValue int
+
+func newCounterValue(val int, p *int) *counterValue {
+	*p = val
+	return (*counterValue)(p)
+}
+
+func (c *counterValue) Set(s string) error {
+	switch s {
+	case "true":
+		*c++
+		return nil
+	case "false":
+		*c = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return numError(err)
+	}
+	if v < 0 {
+		return errRange
+	}
+	*c = counterValue(v)
+	return nil
+}
+
+func (c *counterValue) Get() interface{} { return int(*c) }
+
+func (c *counterValue) String() string { return strconv.Itoa(int(*c)) }
+
+func (c *counterValue) IsBoolFlag() bool { return true }
+
+// negatedValue implements flag.Value for the "no-" form of a boolean
+// option, setting it sets the option to the opposite value.
+type negatedValue struct {
+	target flag.Value
+}
+
+func (n *negatedValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errParse
+	}
+	return n.target.Set(strconv.FormatBool(!v))
+}
+
+func (n *negatedValue) String() string { return "" }
+
+func (n *negatedValue) IsBoolFlag() bool { return true }
+
+// funcValue implements flag.Value calling a function for each value set
+type funcValue func(string) error
+
+func (f funcValue) Set(s string) error { return f(s) }
+
+func (f funcValue) String() string { return "" }
+
+// sliceValue is implemented by option values that accumulate each
+// time the option is given.
+type sliceValue interface {
+	flag.Value
+	// separator returns the string used to split a value into a list,
+	// an empty string if values are not split.
+	separator() string
+}
+
+// splitValue splits s on sep trimming spaces and skipping empty values,
+// if sep is an empty string s is returned as is.
+func splitValue(s string, sep string) []string {
+	if sep == "" {
+		return []string{s}
+	}
+	values := []string{}
+	for _, val := range strings.Split(s, sep) {
+		val = strings.TrimSpace(val)
+		if val != "" {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
+// stringSliceValue implements flag.Value for a []string. The first value
+// set replaces the default, following values are appended.
+type stringSliceValue struct {
+	p       *[]string
+	sep     string
+	changed bool
+}
+
+func newStringSliceValue(val []string, sep string, p *[]string) *stringSliceValue {
+	*p = append([]string{}, val...)
+	return &stringSliceValue{p: p, sep: sep}
+}
+
+func (s *stringSliceValue) Set(val string) error {
+	if s.changed == false {
+		*s.p = []string{}
+		s.changed = true
+	}
+	*s.p = append(*s.p, splitValue(val, s.sep)...)
+	return nil
+}
+
+func (s *stringSliceValue) Get() interface{} { return *s.p }
+
+func (s *stringSliceValue) String() string {
+	if s.p == nil {
+		return ""
+	}
+	if s.sep == "" {
+		return strings.Join(*s.p, ",")
+	}
+	return strings.Join(*s.p, s.sep)
+}
+
+func (s *stringSliceValue) separator() string { return s.sep }
+
+func (s *stringSliceValue) replaceOnSet() { s.changed = false }
+
+// intSliceValue implements flag.Value for a []int. The first value
+// set replaces the default, following values are appended.
+type intSliceValue struct {
+	p       *[]int
+	sep     string
+	changed bool
+}
+
+func newIntSliceValue(val []int, sep string, p *[]int) *intSliceValue {
+	*p = append([]int{}, val...)
+	return &intSliceValue{p: p, sep: sep}
+}
+
+func (s *intSliceValue) Set(val string) error {
+	values := []int{}
+	for _, v := range splitValue(val, s.sep) {
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 0, strconv.IntSize)
+		if err != nil {
+			return numError(err)
+		}
+		values = append(values, int(i))
+	}
+	if s.changed == false {
+		*s.p = []int{}
+		s.changed = true
+	}
+	*s.p = append(*s.p, values...)
+	return nil
+}
+
+func (s *intSliceValue) Get() interface{} { return *s.p }
+
+func (s *intSliceValue) String() string {
+	if s.p == nil {
+		return ""
+	}
+	sep := s.sep
+	if sep == "" {
+		sep = ","
+	}
+	parts := []string{}
+	for _, i := range *s.p {
+		parts = append(parts, strconv.Itoa(i))
+	}
+	return strings.Join(parts, sep)
+}
+
+func (s *intSliceValue) separator() string { return s.sep }
+
+func (s *intSliceValue) replaceOnSet() { s.changed = false }
+
+// choiceValue implements flag.Value for a string limited to a set of
+// allowed values, e.g. json, csv or tsv.
+type choiceValue struct {
+	p       *string
+	choices []string
+}
+
+// newChoiceValue returns a choiceValue, like the flag package does for a
+// redefined flag it panics if the default isn't empty or one of choices.
+func newChoiceValue(names string, val string, choices []string, p *string) *choiceValue {
+	c := &choiceValue{p: p, choices: choices}
+	if val != "" {
+		if err := c.Set(val); err != nil {
+			panic(fmt.Sprintf("%s default %q %s", opsLabel(splitOps(names), false), val, err))
+		}
+	}
+	*p = val
+	return c
+}
+
+func (c *choiceValue) Set(val string) error {
+	for _, choice := range c.choices {
+		if val == choice {
+			*c.p = val
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(c.choices, ", "))
+}
+
+func (c *choiceValue) Get() interface{} { return *c.p }
+
+func (c *choiceValue) String() string {
+	if c.p == nil {
+		return ""
+	}
+	return *c.p
+}
+
+// Choices returns the allowed values
+func (c *choiceValue) Choices() []string { return c.choices }
+
+// formatValue implements flag.Value for a boolean option that may also
+// name an output format, e.g. "-show-config" or "-show-config=json".
+// Given alone the first format is used.
+type formatValue struct {
+	p       *bool
+	format  *string
+	formats []string
+}
+
+func newFormatValue(p *bool, format *string, formats []string) *formatValue {
+	*p, *format = false, formats[0]
+	return &formatValue{p: p, format: format, formats: formats}
+}
+
+func (f *formatValue) Set(val string) error {
+	switch val {
+	case "true":
+		*f.p, *f.format = true, f.formats[0]
+		return nil
+	case "false":
+		*f.p = false
+		return nil
+	}
+	for _, format := range f.formats {
+		if val == format {
+			*f.p, *f.format = true, val
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(f.formats, ", "))
+}
+
+func (f *formatValue) Get() interface{} { return *f.p }
+
+func (f *formatValue) String() string {
+	if f.p == nil || *f.p == false {
+		return "false"
+	}
+	return *f.format
+}
+
+func (f *formatValue) IsBoolFlag() bool { return true }
+
+func (f *formatValue) Type() string { return "bool" }
+
+// Choices returns the formats allowed
+func (f *formatValue) Choices() []string { return f.formats }
+
+// TimeFormats describes the values accepted by ParseTime, it is used to
+// document time options and environment variables.
+const TimeFormats = `RFC3339, YYYY-MM-DD, "YYYY-MM-DD HH:MM:SS", now, today, yesterday or relative e.g. "7d ago"`
+
+// timeLayouts are the absolute time layouts accepted by ParseTime
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeUnits are the units accepted in relative times besides those of
+// time.ParseDuration
+var timeUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseTime parses an RFC3339 timestamp, a plain date (YYYY-MM-DD) or
+// date and time (YYYY-MM-DD HH:MM:SS) in the local time zone, "now",
+// "today", "yesterday" or a relative time such as "7d ago", "36h ago" or
+// "2w ago" (units are s, m, h, d and w).
+func ParseTime(s string) (time.Time, error) {
+	return parseTime(s, time.Now())
+}
+
+// parseTime implements ParseTime relative to now
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+	if strings.HasSuffix(s, " ago") {
+		d, err := parseRelative(strings.TrimSpace(strings.TrimSuffix(s, " ago")))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected %s", TimeFormats)
+}
+
+// parseRelative parses the duration of a relative time, e.g. "7d"
+func parseRelative(s string) (time.Duration, error) {
+	for unit, size := range timeUnits {
+		if strings.HasSuffix(s, unit) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, unit), 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid relative time %q", s+" ago")
+			}
+			return time.Duration(n * float64(size)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid relative time %q", s+" ago")
+	}
+	return d, nil
+}
+
+// timeValue implements flag.Value for a time.Time
+type timeValue time.Time
+
+func newTimeValue(val time.Time, p *time.Time) *timeValue {
+	*p = val
+	return (*timeValue)(p)
+}
+
+func (t *timeValue) Set(s string) error {
+	v, err := ParseTime(s)
+	if err != nil {
+		return err
+	}
+	*t = timeValue(v)
+	return nil
+}
+
+func (t *timeValue) Get() interface{} { return time.Time(*t) }
+
+func (t *timeValue) String() string {
+	if t == nil || time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
@@ -24,7 +24,6 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"sort"
 	"strings"
 	"time"
 )
@@ -45,8 +44,12 @@ type Verb struct {
 	// SectionNo corresponds to the manual section number (used in generating man pages)
 	SectionNo int
 
-	// options holds documentation strings for flags associated with verb
-	options map[string]string
+	// options describes the options associated with verb in the order declared
+	options []*Option
+	// group is the current group name assigned to options as they are declared
+	group string
+	// constraints holds rules between options checked after parsing
+	constraints []*constraint
 
 	// Fn holds the main function associated with the verb, often is passed
 	// stdin, stdout and stnerror returns a value suitable for passing to
@@ -56,17 +59,37 @@ type Verb struct {
 	// FlagSet holds the parsable options associated with the verb.
 	FlagSet *flag.FlagSet
 
+	// GNUStyle is true then options are parsed and documented GNU/POSIX
+	// style, e.g. "-o, --output", "--output=FILE", bundled short options
+	// like "-qp" and "--" to end option processing.
+	GNUStyle bool
+
+	// Interspersed is true then options may appear after positional
+	// arguments, option processing continues until "--".
+	Interspersed bool
+
+	// NegatableBools is true then every boolean option with a long name
+	// also accepts a "no-" form, e.g. "-no-color".
+	NegatableBools bool
+
 	// params holds description of non-option command line parameters
 	// e.g. for parameters `FILENAME [URL]` the options
 	// array would hold "FILENAME", "[URL]".
 	params []string
+	// paramSpec describes the positional parameters validated after parsing
+	paramSpec []*Param
+	// paramValues holds the converted positional parameters by name
+	paramValues map[string][]interface{}
+	// parent is the Cli that created the verb with Cli.NewVerb(), its
+	// GNUStyle, Interspersed and NegatableBools also apply to the verb.
+	parent *Cli
 }
 
 // NewVerb creates an Verb instance, and describes the running of the
 // command line interface making it easy to expose the functionality
 // in packages as command line tools.
 func NewVerb(name, usage string, fn func(io.Reader, io.Writer, io.Writer, []string, *flag.FlagSet) int) *Verb {
-	options := make(map[string]string)
+	options := []*Option{}
 	documentation := make(map[string][]byte)
 	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
 	return &Verb{
@@ -96,24 +119,34 @@ func (v *Verb) AddHelp(keyword string, usage []byte) error {
 // Documentation map and if nothing found looks in Synopsis map
 // and if not there return an empty string not documented string.
 func (v *Verb) Help(keywords ...string) string {
+	v.inherit()
 	var sections []string
 
 	if len(keywords) == 0 {
-		if len(v.params) > 0 {
+		if params := v.paramNames(); len(params) > 0 {
 			sections = append(sections, fmt.Sprintf("VERB\n\n%s", v.Name))
-			sections = append(sections, fmt.Sprintf("    %s %s", v.Name, strings.Join(v.params, " ")))
+			sections = append(sections, fmt.Sprintf("    %s %s", v.Name, strings.Join(params, " ")))
 			if len(v.Usage) != 0 {
 				sections = append(sections, v.Usage)
 			}
 			if len(v.options) > 0 {
-				keys := []string{}
-				for key, _ := range v.options {
-					keys = append(keys, key)
-				}
-				sort.Strings(keys)
 				block := []string{"OPTIONS\n"}
-				for _, key := range keys {
-					block = append(block, fmt.Sprintf("    %s  %s", key, v.options[key]))
+				for i, group := range groupOptions(v.options, v.GNUStyle) {
+					if group.name != "" {
+						if i > 0 {
+							block = append(block, "")
+						}
+						block = append(block, fmt.Sprintf("  %s\n", group.name))
+					}
+					for _, o := range group.options {
+						block = append(block, fmt.Sprintf("    %s  %s", o.synopsis(v.GNUStyle), o.doc()))
+					}
+				}
+				if note := deprecatedNote(v.options, v.GNUStyle); note != "" {
+					block = append(block, "", "    "+note)
+				}
+				if notes := constraintNotes(v.options, v.constraints, v.GNUStyle); len(notes) > 0 {
+					block = append(block, "", "    "+strings.Join(notes, "\n    "))
 				}
 				sections = append(sections, strings.Join(block, "\n"))
 			}
@@ -139,117 +172,213 @@ func (v *Verb) Help(keywords ...string) string {
 	return strings.Join(sections, "\n\n")
 }
 
-// BoolVar updates v.options doc strings, then splits options and calls v.FlagSet.BoolVar()
-func (v *Verb) BoolVar(p *bool, names string, value bool, usage string) {
+// Var updates v.options doc strings, then splits options and calls v.FlagSet.Var()
+// for each name. It lets you define options with your own flag.Value types.
+// A back-quoted name in usage is shown as the value placeholder in the
+// documentation, e.g. "write output to `FILE`" documents "-o, -output FILE".
+func (v *Verb) Var(value flag.Value, names string, usage string) {
 	// Prep to hand off to the flag package
 	ops := splitOps(names)
 	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
+	o := newOption(ops, value, usage, len(v.options))
+	o.Group = v.group
+	v.options = append(v.options, o)
 	// process with flag package
 	for _, op := range ops {
-		v.FlagSet.BoolVar(p, op, value, usage)
+		v.FlagSet.Var(value, op, usage)
 	}
 }
 
-// IntVar updates v.options doc strings, then splits options and calls v.FlagSet.IntVar()
+// OptionGroup sets the group name for the options declared after it,
+// e.g. "Standard Options" or "Application Options". Groups are documented
+// in the order they are first used. An empty string ends the group.
+func (v *Verb) OptionGroup(name string) {
+	v.group = name
+}
+
+// Func defines an option with the names and usage, each time the option
+// is seen fn is called with the option's value. If fn returns a non-nil
+// error it is treated as an invalid value.
+func (v *Verb) Func(names string, usage string, fn func(string) error) {
+	v.Var(funcValue(fn), names, usage)
+}
+
+// BoolVar defines a bool option with the names, default value and usage, see Var()
+func (v *Verb) BoolVar(p *bool, names string, value bool, usage string) {
+	v.Var(newBoolValue(value, p), names, usage)
+}
+
+// IntVar defines an int option with the names, default value and usage, see Var()
 func (v *Verb) IntVar(p *int, names string, value int, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.IntVar(p, op, value, usage)
-	}
+	v.Var(newIntValue(value, p), names, usage)
+}
+
+// CountVar defines a counter option, each time it is given the count is
+// raised by one, e.g. "-v -v -v" or "-vvv" sets 3.
+func (v *Verb) CountVar(p *int, names string, value int, usage string) {
+	v.Var(newCounterValue(value, p), names, usage)
 }
 
-// Int64Var updates v.options doc strings, then splits options and calls v.FlagSet.Int64Var()
+// Int64Var defines an int64 option with the names, default value and usage, see Var()
 func (v *Verb) Int64Var(p *int64, names string, value int64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.Int64Var(p, op, value, usage)
-	}
+	v.Var(newInt64Value(value, p), names, usage)
 }
 
-// UintVar updates v.options doc strings, then splits options and calls v.FlagSet.Int64Var()
+// UintVar defines a uint option with the names, default value and usage, see Var()
 func (v *Verb) UintVar(p *uint, names string, value uint, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.UintVar(p, op, value, usage)
-	}
+	v.Var(newUintValue(value, p), names, usage)
 }
 
-// Uint64Var updates v.options doc strings, then splits options and calls v.FlagSet.Int64Var()
+// Uint64Var defines a uint64 option with the names, default value and usage, see Var()
 func (v *Verb) Uint64Var(p *uint64, names string, value uint64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.Uint64Var(p, op, value, usage)
-	}
+	v.Var(newUint64Value(value, p), names, usage)
 }
 
-// StringVar updates v.options doc strings, then splits options and calls v.FlagSet.StringVar()
+// StringVar defines a string option with the names, default value and usage, see Var()
 func (v *Verb) StringVar(p *string, names string, value string, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.StringVar(p, op, value, usage)
-	}
+	v.Var(newStringValue(value, p), names, usage)
 }
 
-// Float64Var updates v.options doc strings, then splits options and calls v.FlagSet.Float64Var()
+// Float64Var defines a float64 option with the names, default value and usage, see Var()
 func (v *Verb) Float64Var(p *float64, names string, value float64, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.Float64Var(p, op, value, usage)
-	}
+	v.Var(newFloat64Value(value, p), names, usage)
 }
 
-// DurationVar updates v.options doc strings, then splits options and calls v.FlagSet.DurationVar()
+// DurationVar defines a time.Duration option with the names, default value and usage, see Var()
 func (v *Verb) DurationVar(p *time.Duration, names string, value time.Duration, usage string) {
-	// Prep to hand off to the flag package
-	ops := splitOps(names)
-	// Save for our internal option documentation
-	label := opsLabel(ops)
-	v.options[label] = usage
-	// process with flag package
-	for _, op := range ops {
-		op = strings.TrimSpace(op)
-		v.FlagSet.DurationVar(p, op, value, usage)
+	v.Var(newDurationValue(value, p), names, usage)
+}
+
+// TimeVar defines a time.Time option, see ParseTime for the formats accepted
+func (v *Verb) TimeVar(p *time.Time, names string, value time.Time, usage string) {
+	v.Var(newTimeValue(value, p), names, usage)
+}
+
+// FileVar defines an option naming an existing file. A leading "~" and
+// $VAR are expanded and the file is checked when the option is set.
+func (v *Verb) FileVar(p *string, names string, value string, usage string) {
+	v.Var(newPathValue(value, fileKind, p), names, usage)
+}
+
+// DirVar defines an option naming an existing directory. A leading "~"
+// and $VAR are expanded and the directory is checked when the option is set.
+func (v *Verb) DirVar(p *string, names string, value string, usage string) {
+	v.Var(newPathValue(value, dirKind, p), names, usage)
+}
+
+// WritablePathVar defines an option naming a file that can be created or
+// overwritten. A leading "~" and $VAR are expanded and the path is
+// checked when the option is set.
+func (v *Verb) WritablePathVar(p *string, names string, value string, usage string) {
+	v.Var(newPathValue(value, writableKind, p), names, usage)
+}
+
+// StringSliceVar defines a []string option with the names, default value
+// and usage. The option may be repeated (e.g. -i a.json -i b.json), the
+// first occurrence replaces the default. If sep is not an empty string
+// each value is also split on sep (e.g. -fields title,doi,author).
+func (v *Verb) StringSliceVar(p *[]string, names string, value []string, sep string, usage string) {
+	v.Var(newStringSliceValue(value, sep, p), names, usage)
+}
+
+// IntSliceVar defines a []int option with the names, default value
+// and usage. The option may be repeated (e.g. -n 1 -n 2), the first
+// occurrence replaces the default. If sep is not an empty string
+// each value is also split on sep (e.g. -n 1,2,3).
+func (v *Verb) IntSliceVar(p *[]int, names string, value []int, sep string, usage string) {
+	v.Var(newIntSliceValue(value, sep, p), names, usage)
+}
+
+// ChoiceVar defines a string option with the names, default value and
+// usage whose value must be one of choices (e.g. json, csv, tsv). Any
+// other value is rejected when the options are parsed. A default that
+// isn't empty or one of the choices panics, as a redefined flag does.
+func (v *Verb) ChoiceVar(p *string, names string, value string, choices []string, usage string) {
+	v.Var(newChoiceValue(names, value, choices, p), names, usage)
+}
+
+// Required marks the named options as required. Options can be named
+// by any of their names, e.g. "o" or "output" for "o,output". When
+// parsed any required options not given are reported together.
+func (v *Verb) Required(names ...string) error {
+	return setRequired(v.options, names)
+}
+
+// MutuallyExclusive declares that at most one of the named options may
+// be given, e.g. "generate-markdown", "generate-manpage".
+func (v *Verb) MutuallyExclusive(names ...string) error {
+	return v.addConstraint(exclusiveConstraint, "", names)
+}
+
+// Requires declares that if the named option is given the required
+// options must be given too.
+func (v *Verb) Requires(name string, required ...string) error {
+	return v.addConstraint(requiresConstraint, name, required)
+}
+
+// AtLeastOneOf declares that one or more of the named options must be given.
+func (v *Verb) AtLeastOneOf(names ...string) error {
+	return v.addConstraint(atLeastOneConstraint, "", names)
+}
+
+// Implies declares that if the named option is given the implied options
+// are set too, unless given explicitly. Implied options can include a
+// value (e.g. "format=json"), otherwise they are set to "true".
+func (v *Verb) Implies(name string, implied ...string) error {
+	return v.addConstraint(impliesConstraint, name, implied)
+}
+
+// addConstraint validates and saves a constraint
+func (v *Verb) addConstraint(kind int, name string, names []string) error {
+	r, err := newConstraint(v.options, kind, name, names)
+	if err != nil {
+		return err
+	}
+	v.constraints = append(v.constraints, r)
+	return nil
+}
+
+// Negatable adds "no-" forms of the named boolean options, e.g.
+// "-no-color" sets -color to false. They are documented as "-[no-]color".
+// Set v.NegatableBools to add them to all boolean options.
+func (v *Verb) Negatable(names ...string) error {
+	return setNegatable(v.FlagSet, v.options, names)
+}
+
+// inherit turns on GNUStyle, Interspersed and NegatableBools when they
+// are set on the parent Cli, they may be set before or after NewVerb(),
+// then adds the "no-" forms of boolean options if needed.
+func (v *Verb) inherit() {
+	if v.parent != nil {
+		v.GNUStyle = v.GNUStyle || v.parent.GNUStyle
+		v.Interspersed = v.Interspersed || v.parent.Interspersed
+		v.NegatableBools = v.NegatableBools || v.parent.NegatableBools
+	}
+	v.applyNegatable()
+}
+
+// applyNegatable adds the "no-" forms of all boolean options if
+// v.NegatableBools is true.
+func (v *Verb) applyNegatable() {
+	if v.NegatableBools {
+		negateBools(v.FlagSet, v.options)
 	}
 }
 
+// Hide marks the named options as hidden, they are accepted when parsing
+// but not included in the documentation.
+func (v *Verb) Hide(names ...string) error {
+	return setHidden(v.options, names)
+}
+
+// Deprecate marks the named option as deprecated. The option is still
+// accepted but a warning is written when it is used. replacedBy names the
+// replacement option (e.g. "package") or explains why it was retired.
+// Deprecated options are listed in a "Deprecated" note in the documentation.
+func (v *Verb) Deprecate(name string, replacedBy string) error {
+	return setDeprecated(v.options, name, replacedBy)
+}
+
 // HasOptions returns true if len(v.options) > 0, false otherwise
 func (v *Verb) HasOptions() bool {
 	if v.options == nil || len(v.options) == 0 {
@@ -260,23 +389,40 @@ func (v *Verb) HasOptions() bool {
 
 // Option returns an option's document string or unsupported string
 func (v *Verb) Option(op string) string {
-	op = strings.Trim(op, " ")
-	for k, doc := range v.options {
-		if strings.Contains(k, op) {
-			return doc
-		}
+	if o := findOption(v.options, op); o != nil {
+		return o.Usage
 	}
-	return fmt.Sprintf("%q is an unsupported option", op)
+	return fmt.Sprintf("%q is an unsupported option", strings.TrimSpace(op))
+}
+
+// LookupOption returns the Option with a name exactly matching name
+// (e.g. "o", "-o" or "output" for "o,output") or nil if not found.
+func (v *Verb) LookupOption(name string) *Option {
+	return findOption(v.options, name)
+}
+
+// OptionList returns the options in the order they were declared
+func (v *Verb) OptionList() []*Option {
+	return append([]*Option{}, v.options...)
 }
 
 // Options returns a map of option values and doc strings
 func (v *Verb) Options() map[string]string {
-	return v.options
+	v.inherit()
+	return optionDocs(v.options, v.GNUStyle)
 }
 
-// Parse envokes v.FlagSet.Parse() updating variables set in AddOptions
+// Parse processes the options in args updating variables set in AddOptions.
+// Option problems, including missing required options, are returned
+// as a *ParseError. Warnings (e.g. use of a deprecated option) are
+// written to v.FlagSet.Output().
 func (v *Verb) Parse(args []string) error {
-	return v.FlagSet.Parse(args)
+	v.inherit()
+	p := &parser{fs: v.FlagSet, gnu: v.GNUStyle, options: v.options, eout: v.FlagSet.Output(), interspersed: v.Interspersed}
+	if err := p.parse(args); err != nil {
+		return err
+	}
+	return v.validate()
 }
 
 // Args returns v.FlagSet.Args()
@@ -302,6 +448,45 @@ func (v *Verb) SetParams(params ...string) {
 	}
 }
 
+// AddParams describes the positional parameters. After parsing the
+// arguments are checked against them and converted to their type.
+// They are also used to document the parameters in the USAGE line.
+func (v *Verb) AddParams(params ...Param) error {
+	spec := append([]*Param{}, v.paramSpec...)
+	for i := range params {
+		param := params[i]
+		spec = append(spec, &param)
+	}
+	if err := checkParamSpec(spec); err != nil {
+		return err
+	}
+	v.paramSpec = spec
+	return nil
+}
+
+// ParamValues returns the converted values of the named positional
+// parameter, a repeating parameter may have more than one.
+func (v *Verb) ParamValues(name string) []interface{} {
+	return v.paramValues[name]
+}
+
+// ParamValue returns the first converted value of the named positional
+// parameter or nil if it wasn't given.
+func (v *Verb) ParamValue(name string) interface{} {
+	if values := v.paramValues[name]; len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// paramNames returns the parameters as shown in the USAGE line
+func (v *Verb) paramNames() []string {
+	if len(v.paramSpec) > 0 {
+		return paramUsage(v.paramSpec)
+	}
+	return v.params
+}
+
 // String prints an actions' verb and description
 func (v *Verb) String() string {
 	return fmt.Sprintf("%s - %s", v.Name, v.Usage)
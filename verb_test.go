@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"bytes"
 	"flag"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -50,3 +52,133 @@ func TestVerb(t *testing.T) {
 		t.Errorf("expected (count) %d, got %d", expectedI, count)
 	}
 }
+
+func TestVerbVar(t *testing.T) {
+	var (
+		count int
+		ids   []string
+	)
+	verb := NewVerb("harvest", "harvest records", nil)
+	verb.IntVar(&count, "c,count", 1, "count is an integer")
+	verb.Func("id", "add an identifier", func(s string) error {
+		ids = append(ids, s)
+		return nil
+	})
+	if err := verb.Parse([]string{"-c", "2", "-id", "a", "-id", "b", "extra"}); err != nil {
+		t.Errorf("expected Parse() to succeed, %s", err)
+		t.FailNow()
+	}
+	if count != 2 {
+		t.Errorf("expected 2, got %d", count)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected two ids, got %+v", ids)
+	}
+	if verb.NArg() != 1 || verb.Arg(0) != "extra" {
+		t.Errorf("expected [extra], got %+v", verb.Args())
+	}
+}
+
+func TestVerbInterspersed(t *testing.T) {
+	var count int
+	verb := NewVerb("harvest", "harvest records", nil)
+	verb.Interspersed = true
+	verb.IntVar(&count, "c,count", 1, "count is an integer")
+	if err := verb.Parse([]string{"records.csv", "-c", "3"}); err != nil {
+		t.Errorf("expected Parse() to succeed, %s", err)
+	}
+	if count != 3 || verb.NArg() != 1 || verb.Arg(0) != "records.csv" {
+		t.Errorf("expected 3 and [records.csv], got %d, %+v", count, verb.Args())
+	}
+}
+
+func TestVerbSuggestions(t *testing.T) {
+	var count int
+	verb := NewVerb("harvest", "harvest records", nil)
+	verb.GNUStyle = true
+	verb.IntVar(&count, "c,count", 1, "count is an integer")
+	err := verb.Parse([]string{"--cuont", "3"})
+	expectedS := `"--cuont" is an unsupported option, did you mean --count?`
+	if err == nil || err.Error() != expectedS {
+		t.Errorf("expected %q, got %v", expectedS, err)
+	}
+}
+
+func TestVerbCountAndNegatable(t *testing.T) {
+	var verbose int
+	var color bool
+	verb := NewVerb("harvest", "harvest records", nil)
+	verb.AddParams(Param{Name: "FILE", Repeat: true})
+	verb.CountVar(&verbose, "v", 0, "raise verbosity")
+	verb.BoolVar(&color, "color", true, "colorize output")
+	if err := verb.Negatable("color"); err != nil {
+		t.Errorf("expected Negatable() to succeed, %s", err)
+	}
+	if err := verb.Parse([]string{"-vvv", "-no-color"}); err != nil {
+		t.Errorf("expected Parse() to succeed, %s", err)
+	}
+	if verbose != 3 || color != false {
+		t.Errorf("expected verbose 3 and color false, got %d, %t", verbose, color)
+	}
+	if help := verb.Help(); strings.Contains(help, "-[no-]color") == false {
+		t.Errorf("expected -[no-]color in help, got %s", help)
+	}
+}
+
+func TestVerbOptionGroupsInUsage(t *testing.T) {
+	var host, output string
+	var port int
+	var pretty bool
+	app := NewCli(Version)
+	verb := app.NewVerb("harvest", "harvest records", nil)
+	verb.OptionGroup("Network")
+	verb.StringVar(&host, "host", "localhost", "server host")
+	verb.IntVar(&port, "port", 80, "server port")
+	verb.OptionGroup("Output")
+	verb.StringVar(&output, "o,output", "", "output file")
+	verb.BoolVar(&pretty, "pretty", false, "pretty print")
+
+	var buf bytes.Buffer
+	app.Usage(&buf)
+	expected := []string{"Network", "-host STRING", "-port INT", "Output", "-o, -output STRING", "-pretty"}
+	pos := 0
+	for _, s := range expected {
+		i := strings.Index(buf.String()[pos:], s)
+		if i < 0 {
+			t.Errorf("expected %q after position %d in usage, got\n%s", s, pos, buf.String())
+			break
+		}
+		pos += i
+	}
+	for _, render := range []func(io.Writer){app.GenerateMarkdown, app.GenerateManPage} {
+		buf.Reset()
+		render(&buf)
+		for _, s := range []string{"harvest", "Network", "-host STRING", "Output", "-pretty"} {
+			if strings.Contains(buf.String(), s) == false {
+				t.Errorf("expected %q in documentation, got\n%s", s, buf.String())
+			}
+		}
+	}
+}
+
+func TestVerbFollowsParentModes(t *testing.T) {
+	var quiet, pretty, color bool
+	app := NewCli(Version)
+	verb := app.NewVerb("harvest", "harvest records", nil)
+	verb.BoolVar(&quiet, "q,quiet", false, "quiet output")
+	verb.BoolVar(&pretty, "p,pretty", false, "pretty print")
+	verb.BoolVar(&color, "color", true, "colorize output")
+	// NOTE: the modes are set after the verb was created
+	app.GNUStyle = true
+	app.Interspersed = true
+	app.NegatableBools = true
+	if err := verb.Parse([]string{"input.json", "-qp", "--no-color"}); err != nil {
+		t.Errorf("expected Parse() to succeed, %s", err)
+	}
+	if quiet == false || pretty == false || color == true {
+		t.Errorf("expected quiet, pretty and no color, got %t, %t, %t", quiet, pretty, color)
+	}
+	if args := verb.Args(); len(args) != 1 || args[0] != "input.json" {
+		t.Errorf("expected [input.json], got %v", args)
+	}
+}